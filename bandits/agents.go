@@ -0,0 +1,227 @@
+package bandits
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Agent learns to play a bandit. Choose picks the next arm in a context and
+// Update is told the reward it paid. Agents for the plain k-armed problem
+// ignore the context.
+type Agent interface {
+	Choose(ctx Context) int
+	Update(ctx Context, action int, reward float64)
+}
+
+// argmax returns the index of the highest value, breaking ties randomly.
+func argmax(values []float64, rng *rand.Rand) int {
+	best := []int{}
+	for a, v := range values {
+		if len(best) == 0 || v > values[best[0]] {
+			best = []int{a}
+		} else if v == values[best[0]] {
+			best = append(best, a)
+		}
+	}
+	return best[rng.Intn(len(best))]
+}
+
+// estimates holds action-value estimates updated by sample averages, or by
+// a constant step size alpha if alpha is positive.
+type estimates struct {
+	q     []float64
+	n     []int
+	alpha float64
+}
+
+func newEstimates(k int, initial, alpha float64) estimates {
+	q := make([]float64, k)
+	for a := range q {
+		q[a] = initial
+	}
+	return estimates{
+		q:     q,
+		n:     make([]int, k),
+		alpha: alpha,
+	}
+}
+
+func (e *estimates) update(action int, reward float64) {
+	e.n[action]++
+	step := 1 / float64(e.n[action])
+	if e.alpha > 0 {
+		step = e.alpha
+	}
+	e.q[action] += step * (reward - e.q[action])
+}
+
+// EGreedy picks a random arm with probability epsilon and otherwise the arm
+// with the highest estimate.
+type EGreedy struct {
+	estimates
+	epsilon float64
+	rng     *rand.Rand
+}
+
+// NewEGreedy creates an epsilon-greedy agent whose estimates start at
+// initial. With alpha 0 the estimates are sample averages, otherwise they
+// use the constant step size alpha, which tracks nonstationary arms.
+// Optimistic initial values are an EGreedy with a high initial value.
+func NewEGreedy(k int, epsilon, alpha, initial float64, rng *rand.Rand) *EGreedy {
+	return &EGreedy{
+		estimates: newEstimates(k, initial, alpha),
+		epsilon:   epsilon,
+		rng:       rng,
+	}
+}
+
+func (g *EGreedy) Choose(ctx Context) int {
+	if g.rng.Float64() < g.epsilon {
+		return g.rng.Intn(len(g.q))
+	}
+	return argmax(g.q, g.rng)
+}
+
+func (g *EGreedy) Update(ctx Context, action int, reward float64) {
+	g.update(action, reward)
+}
+
+// ReducingEGreedy is an EGreedy whose epsilon starts at 1 and falls to
+// 1/(1+n) after 50n steps.
+type ReducingEGreedy struct {
+	EGreedy
+	step int
+}
+
+func NewReducingEGreedy(k int, rng *rand.Rand) *ReducingEGreedy {
+	return &ReducingEGreedy{
+		EGreedy: *NewEGreedy(k, 1, 0, 0, rng),
+	}
+}
+
+func (g *ReducingEGreedy) Choose(ctx Context) int {
+	g.step++
+	g.epsilon = 1 / float64(1+g.step/50)
+	return g.EGreedy.Choose(ctx)
+}
+
+// UCB picks the arm with the highest upper confidence bound
+// Q(a) + c*sqrt(ln t / N(a)), trying every arm once first.
+type UCB struct {
+	estimates
+	c   float64
+	t   int
+	rng *rand.Rand
+}
+
+func NewUCB(k int, c float64, rng *rand.Rand) *UCB {
+	return &UCB{
+		estimates: newEstimates(k, 0, 0),
+		c:         c,
+		rng:       rng,
+	}
+}
+
+func (u *UCB) Choose(ctx Context) int {
+	u.t++
+	bounds := make([]float64, len(u.q))
+	for a := range u.q {
+		if u.n[a] == 0 {
+			return a // Prefer unexplored actions
+		}
+		bounds[a] = u.q[a] + u.c*math.Sqrt(math.Log(float64(u.t))/float64(u.n[a]))
+	}
+	return argmax(bounds, u.rng)
+}
+
+func (u *UCB) Update(ctx Context, action int, reward float64) {
+	u.update(action, reward)
+}
+
+// Gradient learns a preference for each arm and picks arms from the softmax
+// of the preferences. The average reward so far is the baseline.
+type Gradient struct {
+	h        []float64 // Preferences
+	pi       []float64 // Softmax of h, as of the last Choose
+	alpha    float64
+	baseline float64
+	t        int
+	rng      *rand.Rand
+}
+
+func NewGradient(k int, alpha float64, rng *rand.Rand) *Gradient {
+	return &Gradient{
+		h:     make([]float64, k),
+		pi:    make([]float64, k),
+		alpha: alpha,
+		rng:   rng,
+	}
+}
+
+func (g *Gradient) Choose(ctx Context) int {
+	max := g.h[0]
+	for _, h := range g.h {
+		max = math.Max(max, h)
+	}
+	sum := 0.0
+	for a, h := range g.h {
+		g.pi[a] = math.Exp(h - max)
+		sum += g.pi[a]
+	}
+	for a := range g.pi {
+		g.pi[a] /= sum
+	}
+
+	r := g.rng.Float64()
+	for a, p := range g.pi {
+		r -= p
+		if r < 0 {
+			return a
+		}
+	}
+	return len(g.pi) - 1
+}
+
+func (g *Gradient) Update(ctx Context, action int, reward float64) {
+	g.t++
+	g.baseline += (reward - g.baseline) / float64(g.t)
+	for a := range g.h {
+		if a == action {
+			g.h[a] += g.alpha * (reward - g.baseline) * (1 - g.pi[a])
+		} else {
+			g.h[a] -= g.alpha * (reward - g.baseline) * g.pi[a]
+		}
+	}
+}
+
+// Thompson samples a value for each arm from its posterior and picks the
+// highest. Rewards are taken to be N(q(a), 1) with an N(0, 1) prior on q(a),
+// so the posterior of q(a) after n rewards summing to s is
+// N(s/(n+1), 1/(n+1)).
+type Thompson struct {
+	sum []float64
+	n   []int
+	rng *rand.Rand
+}
+
+func NewThompson(k int, rng *rand.Rand) *Thompson {
+	return &Thompson{
+		sum: make([]float64, k),
+		n:   make([]int, k),
+		rng: rng,
+	}
+}
+
+func (t *Thompson) Choose(ctx Context) int {
+	samples := make([]float64, len(t.sum))
+	for a := range samples {
+		precision := float64(t.n[a] + 1)
+		samples[a] = t.sum[a]/precision + t.rng.NormFloat64()/math.Sqrt(precision)
+	}
+	return argmax(samples, t.rng)
+}
+
+func (t *Thompson) Update(ctx Context, action int, reward float64) {
+	t.n[action]++
+	t.sum[action] += reward
+}
@@ -0,0 +1,104 @@
+package bandits
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Algorithm names an agent and creates a fresh one for each run.
+type Algorithm struct {
+	Name string
+	New  func(k int, rng *rand.Rand) Agent
+}
+
+// ParseAlgorithm parses an agent written as its name followed by its
+// parameters, separated by colons:
+//
+//	egreedy:EPSILON[:ALPHA]  epsilon-greedy, sample averages unless ALPHA is given
+//	reducing                 epsilon-greedy with epsilon falling from 1
+//	ucb:C                    upper confidence bound with exploration C
+//	gradient:ALPHA           gradient bandit with an average reward baseline
+//	optimistic:Q0[:ALPHA]    greedy with initial estimates Q0 and step size ALPHA (default 0.1)
+//	thompson                 Gaussian Thompson sampling
+//	percontext:EPSILON       epsilon-greedy learning each context separately
+//	linucb:ALPHA             disjoint LinUCB with exploration ALPHA
+func ParseAlgorithm(spec string) (Algorithm, error) {
+	parts := strings.Split(spec, ":")
+	name := parts[0]
+	params := make([]float64, len(parts)-1)
+	for i, p := range parts[1:] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return Algorithm{}, fmt.Errorf("%s: invalid parameter %q", spec, p)
+		}
+		params[i] = v
+	}
+
+	// arity checks the number of parameters is between min and max.
+	arity := func(min, max int) error {
+		if len(params) < min || len(params) > max {
+			return fmt.Errorf("%s: %s takes %d to %d parameters", spec, name, min, max)
+		}
+		return nil
+	}
+	// param returns parameter i, or def if it was not given.
+	param := func(i int, def float64) float64 {
+		if i < len(params) {
+			return params[i]
+		}
+		return def
+	}
+
+	var err error
+	var newAgent func(k int, rng *rand.Rand) Agent
+	switch name {
+	case "egreedy":
+		err = arity(1, 2)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewEGreedy(k, param(0, 0), param(1, 0), 0, rng)
+		}
+	case "reducing":
+		err = arity(0, 0)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewReducingEGreedy(k, rng)
+		}
+	case "ucb":
+		err = arity(1, 1)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewUCB(k, param(0, 0), rng)
+		}
+	case "gradient":
+		err = arity(1, 1)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewGradient(k, param(0, 0), rng)
+		}
+	case "optimistic":
+		err = arity(1, 2)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewEGreedy(k, 0, param(1, 0.1), param(0, 0), rng)
+		}
+	case "thompson":
+		err = arity(0, 0)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewThompson(k, rng)
+		}
+	case "percontext":
+		err = arity(1, 1)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewPerContext(k, param(0, 0), rng)
+		}
+	case "linucb":
+		err = arity(1, 1)
+		newAgent = func(k int, rng *rand.Rand) Agent {
+			return NewLinUCB(k, param(0, 0), rng)
+		}
+	default:
+		return Algorithm{}, fmt.Errorf("unknown agent %q", name)
+	}
+	if err != nil {
+		return Algorithm{}, err
+	}
+	return Algorithm{Name: spec, New: newAgent}, nil
+}
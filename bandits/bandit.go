@@ -0,0 +1,85 @@
+// Package bandits is the k-armed bandit testbed: a set of bandit problems,
+// agents that learn to play them and a harness that averages the reward and
+// the percentage of optimal actions over many independent runs.
+package bandits
+
+import (
+	"math/rand"
+)
+
+// Context is what an agent observes before choosing an arm.
+type Context struct {
+	ID       int       // Index of the context, for agents that learn each context separately
+	Features []float64 // Feature vector of the context
+}
+
+// Problem is a bandit problem. Each step the agent observes a context,
+// then pulls an arm.
+type Problem interface {
+	K() int
+	Observe() Context // Draws the context of the next step
+	Optimal() int     // Arm with the highest true value in the current context
+	Pull(a int) float64
+}
+
+// Bandit is a k-armed bandit. Pulling arm a pays a reward drawn from
+// N(q*(a), 1), where the true values q* are drawn from N(0, 1).
+type Bandit struct {
+	q    []float64
+	walk float64 // Standard deviation of the random walk of q* after each pull
+	rng  *rand.Rand
+}
+
+// NewBandit creates a bandit with k arms. If walk is positive the bandit is
+// nonstationary: after every pull each q*(a) takes an independent step drawn
+// from N(0, walk^2).
+func NewBandit(k int, walk float64, rng *rand.Rand) *Bandit {
+	q := make([]float64, k)
+	for a := range q {
+		q[a] = rng.NormFloat64()
+	}
+	return &Bandit{
+		q:    q,
+		walk: walk,
+		rng:  rng,
+	}
+}
+
+func (b *Bandit) K() int {
+	return len(b.q)
+}
+
+// Observe returns the single context of a k-armed bandit, with one
+// constant feature.
+func (b *Bandit) Observe() Context {
+	return Context{Features: []float64{1}}
+}
+
+// Values returns a copy of the true action values q*.
+func (b *Bandit) Values() []float64 {
+	q := make([]float64, len(b.q))
+	copy(q, b.q)
+	return q
+}
+
+// Optimal returns the arm with the highest true value.
+func (b *Bandit) Optimal() int {
+	best := 0
+	for a, v := range b.q {
+		if v > b.q[best] {
+			best = a
+		}
+	}
+	return best
+}
+
+// Pull returns the reward of arm a.
+func (b *Bandit) Pull(a int) float64 {
+	reward := b.q[a] + b.rng.NormFloat64()
+	if b.walk > 0 {
+		for i := range b.q {
+			b.q[i] += b.walk * b.rng.NormFloat64()
+		}
+	}
+	return reward
+}
@@ -0,0 +1,68 @@
+package bandits
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test that the result only depends on the seed, not on the number of workers
+func TestRunDeterministic(t *testing.T) {
+	algorithm, err := ParseAlgorithm("egreedy:0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{K: 10, Steps: 100, Runs: 50, Seed: 7, Workers: 1}
+	serial := Run(algorithm, cfg)
+	cfg.Workers = 4
+	parallel := Run(algorithm, cfg)
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Error("results differ between 1 and 4 workers")
+	}
+}
+
+// Test that every agent learns to pick the optimal arm much more often than chance
+func TestAgentsLearn(t *testing.T) {
+	cfg := Config{K: 10, Steps: 1000, Runs: 200, Seed: 1, Workers: 4}
+	for _, spec := range []string{"egreedy:0.1", "reducing", "ucb:1", "gradient:0.1", "optimistic:5", "thompson"} {
+		algorithm, err := ParseAlgorithm(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := Run(algorithm, cfg)
+		optimal := 0.0
+		for _, o := range result.Optimal[900:] {
+			optimal += o / 100
+		}
+		if optimal < 0.6 {
+			t.Errorf("%s: optimal action %.2f over the last 100 steps, want at least 0.6", spec, optimal)
+		}
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for _, spec := range []string{"egreedy", "ucb:1:2", "thompson:1", "egreedy:x", "softmax"} {
+		if _, err := ParseAlgorithm(spec); err == nil {
+			t.Errorf("ParseAlgorithm(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+// Test that the contextual agents learn the best arm of each context, which
+// a context-free agent cannot
+func TestContextualAgents(t *testing.T) {
+	cfg := Config{K: 5, Steps: 1000, Runs: 100, Seed: 1, Workers: 4, Contexts: 5, Features: 3}
+	for spec, want := range map[string]float64{"percontext:0.1": 0.6, "linucb:1": 0.7} {
+		algorithm, err := ParseAlgorithm(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := Run(algorithm, cfg)
+		optimal := 0.0
+		for _, o := range result.Optimal[900:] {
+			optimal += o / 100
+		}
+		if optimal < want {
+			t.Errorf("%s: optimal action %.2f over the last 100 steps, want at least %.1f", spec, optimal, want)
+		}
+	}
+}
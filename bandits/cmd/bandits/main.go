@@ -0,0 +1,89 @@
+// Command bandits runs the k-armed bandit testbed and writes the average
+// reward and percentage of optimal actions at each step as CSV.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/param108/reinforcement-learning/bandits"
+)
+
+func main() {
+	k := flag.Int("k", 10, "arms per bandit")
+	steps := flag.Int("steps", 1000, "steps per run")
+	runs := flag.Int("runs", 2000, "runs averaged for each agent")
+	walk := flag.Float64("walk", 0, "standard deviation of the random walk of the true values per step, 0 for stationary arms")
+	workers := flag.Int("workers", runtime.NumCPU(), "runs played in parallel")
+	seed := flag.Int64("seed", 1, "random seed")
+	contexts := flag.Int("contexts", 0, "number of contexts of a contextual bandit, 0 for the k-armed bandit")
+	features := flag.Int("features", 5, "features per context")
+	agents := flag.String("agents", "", "comma separated agents, see bandits.ParseAlgorithm (default depends on -contexts)")
+	out := flag.String("out", "", "CSV output file (default stdout)")
+	flag.Parse()
+
+	if *agents == "" {
+		*agents = "egreedy:0.1,egreedy:0.2,reducing,ucb:1,ucb:10,gradient:0.1,optimistic:5,thompson"
+		if *contexts > 0 {
+			*agents = "egreedy:0.1,ucb:1,percontext:0.1,linucb:1"
+		}
+	}
+
+	algorithms := []bandits.Algorithm{}
+	for _, spec := range strings.Split(*agents, ",") {
+		algorithm, err := bandits.ParseAlgorithm(strings.TrimSpace(spec))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error parsing agents:", err)
+			os.Exit(2)
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+
+	cfg := bandits.Config{
+		K:       *k,
+		Steps:   *steps,
+		Runs:    *runs,
+		Walk:    *walk,
+		Workers: *workers,
+		Seed:    *seed,
+
+		Contexts: *contexts,
+		Features: *features,
+	}
+	if cfg.K < 1 || cfg.Steps < 1 || cfg.Runs < 1 || cfg.Features < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -k, -steps, -runs and -features must be positive")
+		os.Exit(2)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		fp, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error creating output:", err)
+			os.Exit(2)
+		}
+		defer fp.Close()
+		w = fp
+	}
+
+	results := []bandits.Result{}
+	for _, algorithm := range algorithms {
+		start := time.Now()
+		result := bandits.Run(algorithm, cfg)
+		results = append(results, result)
+
+		last := cfg.Steps - 1
+		fmt.Fprintf(os.Stderr, "%-16s final reward %.3f, optimal action %5.1f%% (%s)\n",
+			algorithm.Name, result.Reward[last], 100*result.Optimal[last], time.Since(start).Round(time.Millisecond))
+	}
+
+	if err := bandits.WriteCSV(w, results); err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing CSV:", err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,201 @@
+package bandits
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ContextualBandit is a k-armed bandit whose arm values depend on a context.
+// Each context c has a feature vector x_c drawn from N(0, I) and each arm a
+// a weight vector theta_a drawn from N(0, I/d), so the true value
+// q*(c, a) = theta_a . x_c has unit variance like the k-armed testbed.
+// Pulling arm a in context c pays a reward drawn from N(q*(c, a), 1).
+type ContextualBandit struct {
+	contexts [][]float64 // Features of each context
+	theta    [][]float64 // Weights of each arm
+	current  int         // Context of the current step
+	walk     float64     // Standard deviation of the random walk of theta after each pull
+	rng      *rand.Rand
+}
+
+// NewContextualBandit creates a bandit with k arms and contexts contexts of
+// d features. If walk is positive every weight takes an independent random
+// walk step drawn from N(0, walk^2/d) after each pull.
+func NewContextualBandit(k, contexts, d int, walk float64, rng *rand.Rand) *ContextualBandit {
+	scale := 1 / math.Sqrt(float64(d))
+	b := &ContextualBandit{
+		contexts: make([][]float64, contexts),
+		theta:    make([][]float64, k),
+		walk:     walk * scale,
+		rng:      rng,
+	}
+	for c := range b.contexts {
+		b.contexts[c] = make([]float64, d)
+		for i := range b.contexts[c] {
+			b.contexts[c][i] = rng.NormFloat64()
+		}
+	}
+	for a := range b.theta {
+		b.theta[a] = make([]float64, d)
+		for i := range b.theta[a] {
+			b.theta[a][i] = scale * rng.NormFloat64()
+		}
+	}
+	return b
+}
+
+func (b *ContextualBandit) K() int {
+	return len(b.theta)
+}
+
+// Observe draws a context uniformly at random.
+func (b *ContextualBandit) Observe() Context {
+	b.current = b.rng.Intn(len(b.contexts))
+	features := make([]float64, len(b.contexts[b.current]))
+	copy(features, b.contexts[b.current])
+	return Context{ID: b.current, Features: features}
+}
+
+// Value returns the true value of arm a in context c.
+func (b *ContextualBandit) Value(c, a int) float64 {
+	return dot(b.theta[a], b.contexts[c])
+}
+
+func (b *ContextualBandit) Optimal() int {
+	best := 0
+	for a := range b.theta {
+		if b.Value(b.current, a) > b.Value(b.current, best) {
+			best = a
+		}
+	}
+	return best
+}
+
+func (b *ContextualBandit) Pull(a int) float64 {
+	reward := b.Value(b.current, a) + b.rng.NormFloat64()
+	if b.walk > 0 {
+		for _, theta := range b.theta {
+			for i := range theta {
+				theta[i] += b.walk * b.rng.NormFloat64()
+			}
+		}
+	}
+	return reward
+}
+
+func dot(x, y []float64) float64 {
+	sum := 0.0
+	for i := range x {
+		sum += x[i] * y[i]
+	}
+	return sum
+}
+
+// PerContext runs a separate epsilon-greedy agent for each context ID. It
+// ignores the features, so it cannot share what it learns between contexts.
+type PerContext struct {
+	epsilon float64
+	k       int
+	agents  map[int]*EGreedy
+	rng     *rand.Rand
+}
+
+func NewPerContext(k int, epsilon float64, rng *rand.Rand) *PerContext {
+	return &PerContext{
+		epsilon: epsilon,
+		k:       k,
+		agents:  map[int]*EGreedy{},
+		rng:     rng,
+	}
+}
+
+func (p *PerContext) agent(id int) *EGreedy {
+	if _, ok := p.agents[id]; !ok {
+		p.agents[id] = NewEGreedy(p.k, p.epsilon, 0, 0, p.rng)
+	}
+	return p.agents[id]
+}
+
+func (p *PerContext) Choose(ctx Context) int {
+	return p.agent(ctx.ID).Choose(ctx)
+}
+
+func (p *PerContext) Update(ctx Context, action int, reward float64) {
+	p.agent(ctx.ID).Update(ctx, action, reward)
+}
+
+// LinUCB models the value of each arm as linear in the features and picks
+// the arm with the highest upper confidence bound theta_a . x +
+// alpha*sqrt(x' A_a^-1 x), where theta_a is the ridge regression estimate
+// from the arm's rewards and A_a = I + sum x x' over its pulls (disjoint
+// LinUCB, Li et al. 2010).
+type LinUCB struct {
+	alpha float64
+	k     int
+	inv   [][][]float64 // A_a^-1 of each arm, kept by Sherman-Morrison updates
+	b     [][]float64   // Sum of reward * x of each arm
+	rng   *rand.Rand
+}
+
+func NewLinUCB(k int, alpha float64, rng *rand.Rand) *LinUCB {
+	return &LinUCB{
+		alpha: alpha,
+		k:     k,
+		rng:   rng,
+	}
+}
+
+// init sizes the model for d features on first use.
+func (l *LinUCB) init(d int) {
+	if l.inv != nil {
+		return
+	}
+	l.inv = make([][][]float64, l.k)
+	l.b = make([][]float64, l.k)
+	for a := range l.inv {
+		l.inv[a] = make([][]float64, d)
+		for i := range l.inv[a] {
+			l.inv[a][i] = make([]float64, d)
+			l.inv[a][i][i] = 1
+		}
+		l.b[a] = make([]float64, d)
+	}
+}
+
+// mul returns m x.
+func mul(m [][]float64, x []float64) []float64 {
+	y := make([]float64, len(m))
+	for i := range m {
+		y[i] = dot(m[i], x)
+	}
+	return y
+}
+
+func (l *LinUCB) Choose(ctx Context) int {
+	x := ctx.Features
+	l.init(len(x))
+	bounds := make([]float64, l.k)
+	for a := range bounds {
+		theta := mul(l.inv[a], l.b[a])
+		bounds[a] = dot(theta, x) + l.alpha*math.Sqrt(dot(x, mul(l.inv[a], x)))
+	}
+	return argmax(bounds, l.rng)
+}
+
+func (l *LinUCB) Update(ctx Context, action int, reward float64) {
+	x := ctx.Features
+	l.init(len(x))
+
+	// (A + x x')^-1 = A^-1 - (A^-1 x)(A^-1 x)' / (1 + x' A^-1 x), A symmetric
+	inv := l.inv[action]
+	ax := mul(inv, x)
+	denom := 1 + dot(x, ax)
+	for i := range inv {
+		for j := range inv[i] {
+			inv[i][j] -= ax[i] * ax[j] / denom
+		}
+	}
+	for i := range x {
+		l.b[action][i] += reward * x[i]
+	}
+}
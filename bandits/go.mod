@@ -0,0 +1,3 @@
+module github.com/param108/reinforcement-learning/bandits
+
+go 1.24.3
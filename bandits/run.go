@@ -0,0 +1,139 @@
+package bandits
+
+import (
+	"encoding/csv"
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+// Config describes an experiment on the testbed.
+type Config struct {
+	K     int     // Arms per bandit
+	Steps int     // Pulls per run
+	Runs  int     // Independent bandits, each played by a fresh agent
+	Walk  float64 // Random walk of q* per step, 0 for stationary arms
+
+	// Contexts selects a ContextualBandit with that many contexts of
+	// Features features. With 0 contexts the problem is a plain Bandit.
+	Contexts int
+	Features int
+
+	Workers int   // Runs played in parallel
+	Seed    int64 // Run r plays the same bandit for every algorithm with the same seed
+}
+
+// Result is the outcome of an experiment, averaged over the runs.
+type Result struct {
+	Name    string
+	Reward  []float64 // Average reward at each step
+	Optimal []float64 // Fraction of runs that chose the optimal arm at each step
+}
+
+// blockRuns is the number of runs summed together before the blocks are
+// combined. Blocks do not depend on the number of workers, so neither does
+// the order of the floating point additions.
+const blockRuns = 16
+
+// Run plays algorithm on cfg.Runs bandits and averages the results. The
+// result only depends on the seed, not on the number of workers.
+func Run(algorithm Algorithm, cfg Config) Result {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	blocks := make([]Result, (cfg.Runs+blockRuns-1)/blockRuns)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range next {
+				sum := Result{
+					Reward:  make([]float64, cfg.Steps),
+					Optimal: make([]float64, cfg.Steps),
+				}
+				for r := b * blockRuns; r < cfg.Runs && r < (b+1)*blockRuns; r++ {
+					run(algorithm, cfg, r, sum)
+				}
+				blocks[b] = sum
+			}
+		}()
+	}
+	for b := range blocks {
+		next <- b
+	}
+	close(next)
+	wg.Wait()
+
+	result := Result{
+		Name:    algorithm.Name,
+		Reward:  make([]float64, cfg.Steps),
+		Optimal: make([]float64, cfg.Steps),
+	}
+	for _, b := range blocks {
+		for t := range result.Reward {
+			result.Reward[t] += b.Reward[t]
+			result.Optimal[t] += b.Optimal[t]
+		}
+	}
+	if cfg.Runs > 0 {
+		for t := range result.Reward {
+			result.Reward[t] /= float64(cfg.Runs)
+			result.Optimal[t] /= float64(cfg.Runs)
+		}
+	}
+	return result
+}
+
+// NewProblem creates the bandit problem described by cfg.
+func NewProblem(cfg Config, rng *rand.Rand) Problem {
+	if cfg.Contexts > 0 {
+		return NewContextualBandit(cfg.K, cfg.Contexts, cfg.Features, cfg.Walk, rng)
+	}
+	return NewBandit(cfg.K, cfg.Walk, rng)
+}
+
+// run plays run r and adds its rewards and optimal choices to sum. The
+// bandit and the agent have separate random sources so that every
+// algorithm faces the same bandits.
+func run(algorithm Algorithm, cfg Config, r int, sum Result) {
+	seed := cfg.Seed + 2*int64(r)
+	bandit := NewProblem(cfg, rand.New(rand.NewSource(seed)))
+	agent := algorithm.New(cfg.K, rand.New(rand.NewSource(seed+1)))
+
+	for t := 0; t < cfg.Steps; t++ {
+		ctx := bandit.Observe()
+		optimal := bandit.Optimal()
+		action := agent.Choose(ctx)
+		reward := bandit.Pull(action)
+		agent.Update(ctx, action, reward)
+
+		sum.Reward[t] += reward
+		if action == optimal {
+			sum.Optimal[t]++
+		}
+	}
+}
+
+// WriteCSV writes results as CSV with one row per algorithm and step.
+func WriteCSV(w io.Writer, results []Result) error {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', 6, 64) }
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"algorithm", "step", "average_reward", "optimal_action"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		for t := range r.Reward {
+			if err := cw.Write([]string{r.Name, strconv.Itoa(t + 1), f(r.Reward[t]), f(r.Optimal[t])}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -199,7 +199,7 @@ func readProbabilityTableFile(filename string) (map[int]float64, error) {
 
 func playRound(
 	p1, p2 *RLPlayer,
-	humanPlayerNum int, isHuman bool, mode string, tree bool, swap bool) (*RLPlayer, []BoardAction, bool, error) {
+	humanPlayerNum int, isHuman bool, mode string) (*RLPlayer, []BoardAction, bool, error) {
 	players := []*RLPlayer{p1, p2}
 	history := []BoardAction{}
 	board := [9]int{}
@@ -211,26 +211,6 @@ func playRound(
 		otherIdx = 1 - curIdx
 	}
 
-	if mode == "learn" && tree {
-		if swap {
-			curIdx, otherIdx = otherIdx, curIdx
-		}
-
-		node := iterateTree(players[curIdx].Player, players[curIdx].Player)
-		for node == nil || hasWon(players[curIdx].Player, node.board) ||
-			hasWon(players[otherIdx].Player, node.board) || !movesRemain(node.board) {
-			if node == nil {
-				clearAllSeen()
-			}
-			node = iterateTree(players[curIdx].Player, players[curIdx].Player)
-		}
-
-		// copy the board from the node
-		for i := 0; i < 9; i++ {
-			board[i] = node.board[i]
-		}
-	}
-
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
@@ -378,16 +358,11 @@ func main() {
 		// Two RL players: X=1, O=2
 		p1 := NewRLPlayer(1)
 		p2 := NewRLPlayer(2)
-		tree := false
 
 		if proc == "proc" {
 			p2.procedural = NewProcedural(2)
 		}
 
-		if proc == "tree" {
-			tree = true
-		}
-
 		totalGames := 5000 // Or pick your number
 		wins := 0
 		losses := 0
@@ -409,7 +384,7 @@ func main() {
 			}
 
 			for i := 0; i < totalGames; i++ {
-				winner, history, _, _ := playRound(p1, p2, 0, false, "learn", tree)
+				winner, history, _, _ := playRound(p1, p2, 0, false, "learn")
 
 				// the boards that were played by the other player
 				// are the result of this players actions and it is
@@ -471,7 +446,7 @@ func main() {
 		p1.ProbabilityTable, _ = readProbabilityTableFile("p1.json")
 
 		fmt.Printf("AI is player %d, Human is player %d\n", aiPlayerNum, humanPlayerNum)
-		player, history, draw, err := playRound(p1, p2, humanPlayerNum, true, "play", false)
+		player, history, draw, err := playRound(p1, p2, humanPlayerNum, true, "play")
 		if err != nil {
 			fmt.Println("Error during game:", err)
 		}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+var valueNames = map[int]string{1: "win", 0: "draw", -1: "loss"}
+
+func audit(args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	model := fs.String("model", "learner_player.json", "model file to audit")
+	side := fs.String("side", "both", "side to audit: X, O or both")
+	fs.Parse(args)
+
+	seats := []int{}
+	switch *side {
+	case "X":
+		seats = append(seats, 1)
+	case "O":
+		seats = append(seats, 2)
+	case "both":
+		seats = append(seats, 1, 2)
+	default:
+		fmt.Println("Invalid side. Use 'X', 'O' or 'both'.")
+		os.Exit(2)
+	}
+
+	lp := player.NewLearnerPlayer(1, 0, 0, "learner")
+	if err := lp.LoadModel(*model); err != nil {
+		fmt.Println("Error loading model:", err)
+		os.Exit(2)
+	}
+
+	total := 0
+	for _, seat := range seats {
+		name := map[int]string{1: "X", 2: "O"}[seat]
+		root := board.NewBoard(1)
+		blunders, checked := eval.Audit(lp, root, seat)
+		total += len(blunders)
+
+		byDepth := map[int][]eval.Blunder{}
+		for _, bl := range blunders {
+			byDepth[bl.Depth] = append(byDepth[bl.Depth], bl)
+		}
+
+		fmt.Printf("Audit as %s: %d positions checked, %d blunders\n", name, checked, len(blunders))
+		for d := 0; d < board.TicTacToe.Cells(); d++ {
+			if len(byDepth[d]) == 0 {
+				continue
+			}
+			fmt.Printf("\nDepth %d: %d blunders\n", d, len(byDepth[d]))
+			for _, bl := range byDepth[d] {
+				fmt.Println()
+				b := bl.State.(*board.Board)
+				b.PrintBoard(b.Get())
+				fmt.Println("Position:", b.String())
+				best := []string{}
+				for _, a := range bl.Best {
+					best = append(best, b.FormatAction(a))
+				}
+				fmt.Printf("Learner plays %s: %s -> %s. Optimal: %s\n", b.FormatAction(bl.Move),
+					valueNames[bl.Value], valueNames[bl.After], strings.Join(best, ", "))
+			}
+		}
+		fmt.Println()
+	}
+
+	if total > 0 {
+		os.Exit(1)
+	}
+	fmt.Println("No blunders found.")
+}
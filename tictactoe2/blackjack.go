@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/blackjack"
+)
+
+// learnBlackjack runs a Monte Carlo method on blackjack and prints the
+// value tables and the policy.
+func learnBlackjack(args []string) {
+	fs := flag.NewFlagSet("blackjack", flag.ExitOnError)
+	method := fs.String("method", "es", "predict (the value of sticking on -stick-on), es (exploring starts control) or offpolicy (importance sampling control)")
+	episodes := fs.Int("episodes", 500000, "hands to learn from")
+	stickOn := fs.Int("stick-on", 20, "the policy predict evaluates sticks on this sum or more")
+	fs.Parse(args)
+
+	switch *method {
+	case "predict":
+		v := blackjack.Predict(blackjack.StickOn(*stickOn), *episodes)
+		fmt.Printf("State values of sticking on %d or more after %d hands:\n\n", *stickOn, *episodes)
+		v.Print("%.3f")
+	case "es", "offpolicy":
+		var q blackjack.Q
+		if *method == "es" {
+			q = blackjack.ExploringStarts(*episodes)
+		} else {
+			q = blackjack.OffPolicy(*episodes)
+		}
+		v := q.Values()
+		fmt.Printf("Optimal policy after %d hands (H hit, S stick):\n\n", *episodes)
+		blackjack.PrintPolicy(q.Greedy())
+		fmt.Println("Optimal state values:")
+		fmt.Println()
+		v.Print("%.3f")
+	default:
+		fmt.Println("Unknown method:", *method)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,205 @@
+// Package blackjack is the blackjack environment of Sutton and Barto's
+// example 5.1 with Monte Carlo methods to learn it. Cards come from an
+// infinite deck, the dealer sticks on 17 or more and the player decides
+// from their sum, the dealer's showing card and whether they hold a usable
+// ace.
+package blackjack
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Actions.
+const (
+	Stick = iota
+	Hit
+)
+
+// State is what the player sees. The player always hits below 12, so only
+// sums from 12 to 21 need a decision.
+type State struct {
+	Sum       int  // Player's sum, 12 to 21
+	Dealer    int  // Dealer's showing card, 1 (ace) to 10
+	UsableAce bool // Whether the player holds an ace counted as 11
+}
+
+// Episode is one hand: the states seen, the actions taken in them and the
+// final reward, +1 for a win, 0 for a draw and -1 for a loss.
+type Episode struct {
+	States  []State
+	Actions []int
+	Reward  float64
+}
+
+// Policy gives the action to take in a state.
+type Policy func(s State) int
+
+// draw deals a card from an infinite deck: face cards count 10 and an
+// ace counts 1.
+func draw() int {
+	return min(rand.Intn(13)+1, 10)
+}
+
+// hand is a sum of cards with an ace counted as 11 if that does not bust.
+type hand struct {
+	sum       int
+	usableAce bool
+}
+
+func (h *hand) add(card int) {
+	h.sum += card
+	if card == 1 && h.sum+10 <= 21 {
+		h.sum += 10
+		h.usableAce = true
+	}
+	if h.sum > 21 && h.usableAce {
+		h.sum -= 10
+		h.usableAce = false
+	}
+}
+
+// Deal returns a random starting state, dealing the player cards until
+// their sum is at least 12, the dealer's hidden card and whether the
+// player was dealt a natural, an ace and a ten.
+func Deal() (State, int, bool) {
+	player := hand{}
+	cards := 0
+	for player.sum < 12 {
+		player.add(draw())
+		cards++
+	}
+	natural := cards == 2 && player.sum == 21
+	return State{Sum: player.sum, Dealer: draw(), UsableAce: player.usableAce}, draw(), natural
+}
+
+// Play plays a hand from start with the dealer's hidden card hidden,
+// taking first as the first action and then following pi. The player's
+// cards are not known, so a natural is not checked for.
+func Play(start State, hidden int, first int, pi Policy) Episode {
+	e := Episode{}
+	player := hand{sum: start.Sum, usableAce: start.UsableAce}
+	dealer := hand{}
+	dealer.add(start.Dealer)
+	dealer.add(hidden)
+
+	s, a := start, first
+	for {
+		e.States = append(e.States, s)
+		e.Actions = append(e.Actions, a)
+		if a == Stick {
+			break
+		}
+		player.add(draw())
+		if player.sum > 21 {
+			e.Reward = -1
+			return e
+		}
+		s = State{Sum: player.sum, Dealer: start.Dealer, UsableAce: player.usableAce}
+		a = pi(s)
+	}
+
+	for dealer.sum < 17 {
+		dealer.add(draw())
+	}
+	switch {
+	case dealer.sum > 21 || player.sum > dealer.sum:
+		e.Reward = 1
+	case player.sum < dealer.sum:
+		e.Reward = -1
+	}
+	return e
+}
+
+// Generate deals a hand and plays it with pi. A natural wins unless the
+// dealer has one too, without the player acting; the episode records it as
+// sticking.
+func Generate(pi Policy) Episode {
+	start, hidden, natural := Deal()
+	if natural {
+		e := Episode{States: []State{start}, Actions: []int{Stick}, Reward: 1}
+		if start.Dealer+hidden == 11 && (start.Dealer == 1 || hidden == 1) {
+			e.Reward = 0
+		}
+		return e
+	}
+	return Play(start, hidden, pi(start), pi)
+}
+
+// Table holds a number for each state, indexed by usable ace (0 or 1),
+// player sum - 12 and dealer card - 1.
+type Table [2][10][10]float64
+
+func (t *Table) At(s State) *float64 {
+	ace := 0
+	if s.UsableAce {
+		ace = 1
+	}
+	return &t[ace][s.Sum-12][s.Dealer-1]
+}
+
+// Print prints the table with each number written by format.
+func (t *Table) Print(format string) {
+	printGrid(func(s State) string {
+		return fmt.Sprintf(format, *t.At(s))
+	})
+}
+
+// PrintPolicy prints pi with H for hit and S for stick.
+func PrintPolicy(pi Policy) {
+	printGrid(func(s State) string {
+		return []string{"S", "H"}[pi(s)]
+	})
+}
+
+// printGrid prints cell for every state, with and without a usable ace,
+// with the player's sum down the side and the dealer's card across the top.
+func printGrid(cell func(s State) string) {
+	for _, ace := range []bool{true, false} {
+		if ace {
+			fmt.Println("Usable ace")
+		} else {
+			fmt.Println("No usable ace")
+		}
+		fmt.Print("    ")
+		for d := 1; d <= 10; d++ {
+			if d == 1 {
+				fmt.Printf(" %6s", "A")
+			} else {
+				fmt.Printf(" %6d", d)
+			}
+		}
+		fmt.Println()
+		for sum := 21; sum >= 12; sum-- {
+			fmt.Printf("%4d", sum)
+			for d := 1; d <= 10; d++ {
+				fmt.Printf(" %6s", cell(State{Sum: sum, Dealer: d, UsableAce: ace}))
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+	}
+}
+
+// States returns every state.
+func States() []State {
+	states := []State{}
+	for _, ace := range []bool{false, true} {
+		for sum := 12; sum <= 21; sum++ {
+			for d := 1; d <= 10; d++ {
+				states = append(states, State{Sum: sum, Dealer: d, UsableAce: ace})
+			}
+		}
+	}
+	return states
+}
+
+// StickOn returns the policy that sticks on sum or more and hits otherwise.
+func StickOn(sum int) Policy {
+	return func(s State) int {
+		if s.Sum >= sum {
+			return Stick
+		}
+		return Hit
+	}
+}
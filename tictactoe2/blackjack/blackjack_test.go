@@ -0,0 +1,81 @@
+package blackjack
+
+import (
+	"math"
+	"testing"
+)
+
+// dealerFinal returns the distribution of the dealer's final sum, with 22
+// standing for bust, from a hand of sum with or without a usable ace.
+func dealerFinal(h hand) map[int]float64 {
+	if h.sum >= 17 {
+		return map[int]float64{min(h.sum, 22): 1}
+	}
+	dist := map[int]float64{}
+	for card := 1; card <= 13; card++ {
+		next := h
+		next.add(min(card, 10))
+		for sum, p := range dealerFinal(next) {
+			dist[sum] += p / 13
+		}
+	}
+	return dist
+}
+
+// stickValue returns the exact value of sticking on sum against the dealer
+// showing card.
+func stickValue(sum, card int) float64 {
+	h := hand{}
+	h.add(card)
+	value := 0.0
+	for final, p := range dealerFinal(h) {
+		switch {
+		case final == 22 || sum > final:
+			value += p
+		case sum < final:
+			value -= p
+		}
+	}
+	return value
+}
+
+// Test Monte Carlo prediction against the exact values of always sticking.
+// Usable aces are dealt too rarely for a tight bound, so only states
+// without one are checked.
+func TestPredict(t *testing.T) {
+	v := Predict(StickOn(12), 500000)
+	for _, s := range States() {
+		if s.Sum == 21 || s.UsableAce {
+			continue // 21 includes naturals
+		}
+		if want := stickValue(s.Sum, s.Dealer); math.Abs(*v.At(s)-want) > 0.08 {
+			t.Errorf("V(%+v) = %.3f, want %.3f", s, *v.At(s), want)
+		}
+	}
+}
+
+// Test that both control methods learn clear-cut decisions of the optimal
+// policy
+func TestControl(t *testing.T) {
+	want := map[State]int{
+		{Sum: 20, Dealer: 10}:                  Stick,
+		{Sum: 20, Dealer: 1}:                   Stick,
+		{Sum: 19, Dealer: 6}:                   Stick,
+		{Sum: 12, Dealer: 10}:                  Hit,
+		{Sum: 13, Dealer: 8}:                   Hit,
+		{Sum: 15, Dealer: 1}:                   Hit,
+		{Sum: 21, Dealer: 10, UsableAce: true}: Stick,
+		{Sum: 13, Dealer: 5, UsableAce: true}:  Hit,
+		{Sum: 15, Dealer: 9, UsableAce: true}:  Hit,
+	}
+	methods := map[string]func(int) Q{"exploring starts": ExploringStarts, "off-policy": OffPolicy}
+	for name, learn := range methods {
+		q := learn(500000)
+		pi := q.Greedy()
+		for s, a := range want {
+			if pi(s) != a {
+				t.Errorf("%s: %+v: got %s, want %s", name, s, []string{"stick", "hit"}[pi(s)], []string{"stick", "hit"}[a])
+			}
+		}
+	}
+}
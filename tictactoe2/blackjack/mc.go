@@ -0,0 +1,112 @@
+package blackjack
+
+import (
+	"math/rand"
+)
+
+// Q holds an action value table for each action.
+type Q [2]Table
+
+// Greedy returns the policy that takes the action with the highest value,
+// sticking on ties.
+func (q *Q) Greedy() Policy {
+	return func(s State) int {
+		if *q[Hit].At(s) > *q[Stick].At(s) {
+			return Hit
+		}
+		return Stick
+	}
+}
+
+// Values returns the value of the greedy action in each state.
+func (q *Q) Values() Table {
+	v := Table{}
+	for _, s := range States() {
+		*v.At(s) = max(*q[Stick].At(s), *q[Hit].At(s))
+	}
+	return v
+}
+
+// Predict estimates the state values of pi by first-visit Monte Carlo
+// prediction, averaging the returns that follow the first visit to each
+// state over episodes hands. Rewards are not discounted.
+func Predict(pi Policy, episodes int) Table {
+	v, n := Table{}, Table{}
+	for i := 0; i < episodes; i++ {
+		e := Generate(pi)
+		seen := map[State]bool{}
+		for _, s := range e.States {
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			*n.At(s)++
+			*v.At(s) += (e.Reward - *v.At(s)) / *n.At(s)
+		}
+	}
+	return v
+}
+
+// ExploringStarts finds the optimal policy by Monte Carlo control with
+// exploring starts: every hand starts in a random state with a random
+// first action and then follows the greedy policy, which starts as
+// sticking on 20 or 21.
+func ExploringStarts(episodes int) Q {
+	q, n := Q{}, Q{}
+	// nudge the values so that the greedy policy starts as sticking on 20
+	// or 21; the first return in a state replaces the nudge
+	for _, s := range States() {
+		if s.Sum < 20 {
+			*q[Hit].At(s) = 1e-9
+		}
+	}
+	pi := q.Greedy()
+	states := States()
+	for i := 0; i < episodes; i++ {
+		start := states[rand.Intn(len(states))]
+		e := Play(start, draw(), rand.Intn(2), pi)
+
+		type pair struct {
+			s State
+			a int
+		}
+		seen := map[pair]bool{}
+		for t, s := range e.States {
+			a := e.Actions[t]
+			if seen[pair{s, a}] {
+				continue
+			}
+			seen[pair{s, a}] = true
+			*n[a].At(s)++
+			*q[a].At(s) += (e.Reward - *q[a].At(s)) / *n[a].At(s)
+		}
+	}
+	return q
+}
+
+// OffPolicy finds the optimal policy by off-policy Monte Carlo control
+// with weighted importance sampling. Hands are played by a behaviour
+// policy that hits or sticks with equal probability, and the returns are
+// weighted by how likely the greedy target policy was to take the same
+// actions. An episode only teaches the target policy up to the last action
+// where the two policies differ.
+func OffPolicy(episodes int) Q {
+	q, c := Q{}, Q{}
+	behaviour := func(s State) int { return rand.Intn(2) }
+	const behaviourProb = 0.5
+	target := q.Greedy()
+	for i := 0; i < episodes; i++ {
+		e := Generate(behaviour)
+		w := 1.0
+		for t := len(e.States) - 1; t >= 0; t-- {
+			s, a := e.States[t], e.Actions[t]
+			*c[a].At(s) += w
+			*q[a].At(s) += w / *c[a].At(s) * (e.Reward - *q[a].At(s))
+			if target(s) != a {
+				break
+			}
+			w /= behaviourProb
+		}
+	}
+	return q
+}
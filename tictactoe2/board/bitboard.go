@@ -0,0 +1,336 @@
+package board
+
+import (
+	"math/bits"
+	"strconv"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Bitboard is a board stored as one bit mask of cells per mark. A move
+// checks only the lines through its cell, each with a single mask
+// comparison, the empty cells are the complement of the masks and the ID
+// is kept up to date by every move. Bitboard has the API of Board and its
+// states have the same keys, so models learned on one can be played on the
+// other.
+type Bitboard struct {
+	cfg    Config
+	geo    *geometry
+	marks  [3]uint64 // Cells holding each mark, 1 - X 2 - O
+	start  int
+	player int
+	status int   // Result of CheckWin, updated by MakeMove
+	cells  int64 // Base-3 encoding of the cells, the first part of ID
+
+	// Moves played and taken back, latest last, each as cell*2 + mark-1.
+	// They are arrays so that copying a Bitboard is a single allocation.
+	moves  [MaxIDCells]uint8
+	played int
+	undone [MaxIDCells]uint8
+	redo   int
+}
+
+func NewBitboard(start int) *Bitboard {
+	return NewBitboardConfig(TicTacToe, start)
+}
+
+// NewBitboardConfig creates an empty bitboard for cfg. cfg must be valid.
+func NewBitboardConfig(cfg Config, start int) *Bitboard {
+	return &Bitboard{
+		cfg:    cfg,
+		geo:    geometryFor(cfg),
+		start:  start,
+		player: start,
+	}
+}
+
+// Clone returns an independent copy of the board.
+func (b *Bitboard) Clone() *Bitboard {
+	c := *b
+	return &c
+}
+
+func (b *Bitboard) Config() Config {
+	return b.cfg
+}
+
+// ConfigKey returns the same key as Board.ConfigKey, for env.Configured.
+func (b *Bitboard) ConfigKey() string {
+	return b.cfg.String()
+}
+
+func (b *Bitboard) Width() int {
+	return b.cfg.Width
+}
+
+func (b *Bitboard) Height() int {
+	return b.cfg.Height
+}
+
+// Index returns the cell index of (x, y), or -1 if it is off the board.
+func (b *Bitboard) Index(x, y int) int {
+	if x < 0 || x >= b.cfg.Width || y < 0 || y >= b.cfg.Height {
+		return -1
+	}
+	return x + b.cfg.Width*y
+}
+
+// Get returns the marks of the cells, as Board.Get does.
+func (b *Bitboard) Get() []int {
+	brd := make([]int, b.cfg.Cells())
+	for mark := 1; mark <= 2; mark++ {
+		for m := b.marks[mark]; m != 0; m &= m - 1 {
+			brd[bits.TrailingZeros64(m)] = mark
+		}
+	}
+	return brd
+}
+
+func (b *Bitboard) GetStart() int {
+	return b.start
+}
+
+func (b *Bitboard) NextPlayer() int {
+	return b.player
+}
+
+// DefaultMark returns the mark player places when the move does not say.
+func (b *Bitboard) DefaultMark(player int) int {
+	return b.cfg.Rules.defaultMark(player)
+}
+
+// empty returns the mask of the empty cells.
+func (b *Bitboard) empty() uint64 {
+	return b.geo.full &^ (b.marks[1] | b.marks[2])
+}
+
+func (b *Bitboard) GetPossibleMoves() []Action {
+	var actions []Action
+	for m := b.empty(); m != 0; m &= m - 1 {
+		idx := bits.TrailingZeros64(m)
+		for _, mark := range b.cfg.Rules.marks(b.player) {
+			actions = append(actions, Action{X: idx % b.cfg.Width, Y: idx / b.cfg.Width, Player: b.player, Mark: mark})
+		}
+	}
+	return actions
+}
+
+// ID returns the same ID as Board.ID for the same position.
+func (b *Bitboard) ID() int64 {
+	return (b.cells*2+int64(b.start-1))*2 + int64(b.player-1)
+}
+
+// MakeMove places player's default mark at (x, y).
+// Returns true if move succeeded, false if the move was not legal.
+func (b *Bitboard) MakeMove(x, y, player int) bool {
+	return b.MakeMoveMark(x, y, player, b.DefaultMark(player))
+}
+
+// MakeMoveMark is MakeMove for rules that let the player choose the mark.
+// A new move clears the moves RedoMove would replay.
+func (b *Bitboard) MakeMoveMark(x, y, player, mark int) bool {
+	idx := b.Index(x, y)
+	if b.player != player || idx < 0 || !b.play(idx, mark) {
+		return false
+	}
+	b.redo = 0
+	return true
+}
+
+// play places mark at cell idx for the player to move.
+func (b *Bitboard) play(idx, mark int) bool {
+	bit := uint64(1) << idx
+	if b.status != 0 || !b.cfg.Rules.allows(b.player, mark) || b.empty()&bit == 0 {
+		return false
+	}
+
+	b.marks[mark] |= bit
+	b.cells += int64(mark) * b.geo.place[idx]
+	b.moves[b.played] = uint8(idx*2 + mark - 1)
+	b.played++
+
+	lineMark := 0
+	for _, line := range b.geo.cellMasks[idx] {
+		if b.marks[mark]&line == line {
+			lineMark = mark
+			break
+		}
+	}
+	b.status = b.cfg.Rules.outcome(lineMark, b.player, b.empty() == 0)
+	b.player = 3 - b.player
+	return true
+}
+
+// UndoMove takes back the last move. It returns false if no move has been
+// made since the board was created.
+func (b *Bitboard) UndoMove() bool {
+	if b.played == 0 {
+		return false
+	}
+	b.played--
+	move := b.moves[b.played]
+	idx, mark := int(move/2), int(move%2)+1
+
+	b.marks[mark] &^= uint64(1) << idx
+	b.cells -= int64(mark) * b.geo.place[idx]
+	b.status = 0 // moves are only made while the game is being played
+	b.player = 3 - b.player
+	b.undone[b.redo] = move
+	b.redo++
+	return true
+}
+
+// RedoMove replays the last move taken back by UndoMove. It returns false
+// if there is none, or a move has been made since.
+func (b *Bitboard) RedoMove() bool {
+	if b.redo == 0 {
+		return false
+	}
+	b.redo--
+	move := b.undone[b.redo]
+	return b.play(int(move/2), int(move%2)+1)
+}
+
+// Moves returns the moves made since the board was created, in the order
+// they were made.
+func (b *Bitboard) Moves() []Action {
+	moves := make([]Action, b.played)
+	player := b.start
+	for i, move := range b.moves[:b.played] {
+		idx := int(move / 2)
+		moves[i] = Action{X: idx % b.cfg.Width, Y: idx / b.cfg.Width, Player: player, Mark: int(move%2) + 1}
+		player = 3 - player
+	}
+	return moves
+}
+
+// CheckWin returns the status of the game as Board.CheckWin does.
+func (b *Bitboard) CheckWin() int {
+	return b.status
+}
+
+// Board returns the position as a Board, with the same moves played.
+func (b *Bitboard) Board() *Board {
+	brd := NewBoardConfig(b.cfg, b.start)
+	for _, move := range b.Moves() {
+		brd.MakeMoveMark(move.X, move.Y, move.Player, move.Mark)
+	}
+	return brd
+}
+
+// Print prints the board as Board.Print does.
+func (b *Bitboard) Print() {
+	b.Board().Print()
+}
+
+// env.State, with the actions of Board
+
+// ActionAt returns the action that places the default mark of the player
+// to move at (x, y).
+func (b *Bitboard) ActionAt(x, y int) env.Action {
+	return b.ActionMark(x, y, b.DefaultMark(b.player))
+}
+
+// ActionMark returns the action that places mark at (x, y).
+func (b *Bitboard) ActionMark(x, y, mark int) env.Action {
+	a := env.Action(b.Index(x, y))
+	if b.cfg.Rules == Wild && mark == 2 {
+		a += env.Action(b.cfg.Cells())
+	}
+	return a
+}
+
+// Coords returns the (x, y) cell marked by a.
+func (b *Bitboard) Coords(a env.Action) (int, int) {
+	idx := int(a) % b.cfg.Cells()
+	return idx % b.cfg.Width, idx / b.cfg.Width
+}
+
+// Mark returns the mark a places.
+func (b *Bitboard) Mark(a env.Action) int {
+	if b.cfg.Rules == Wild {
+		return 1 + int(a)/b.cfg.Cells()
+	}
+	return b.DefaultMark(b.player)
+}
+
+func (b *Bitboard) Player() int {
+	return b.player
+}
+
+func (b *Bitboard) Actions() []env.Action {
+	if b.status != 0 {
+		return []env.Action{}
+	}
+	empty := b.empty()
+	n := bits.OnesCount64(empty)
+	wild := b.cfg.Rules == Wild
+	if wild {
+		n *= 2
+	}
+	actions := make([]env.Action, 0, n)
+	for m := empty; m != 0; m &= m - 1 {
+		idx := env.Action(bits.TrailingZeros64(m))
+		actions = append(actions, idx)
+		if wild {
+			actions = append(actions, idx+env.Action(b.cfg.Cells()))
+		}
+	}
+	return actions
+}
+
+func (b *Bitboard) Apply(a env.Action) (env.State, bool) {
+	next := b.Clone()
+	if !next.Play(a) {
+		return nil, false
+	}
+	return next, true
+}
+
+// Play plays a in place, for env.Mutable.
+func (b *Bitboard) Play(a env.Action) bool {
+	cells := b.cfg.Cells()
+	mark := b.DefaultMark(b.player)
+	if b.cfg.Rules == Wild {
+		mark = 1 + int(a)/cells
+		a %= env.Action(cells)
+	}
+	if a < 0 || int(a) >= cells || !b.play(int(a), mark) {
+		return false
+	}
+	b.redo = 0
+	return true
+}
+
+// Copy returns Clone as an env.Mutable.
+func (b *Bitboard) Copy() env.Mutable {
+	return b.Clone()
+}
+
+// History returns the moves made since the board was created as actions,
+// for env.Recorded.
+func (b *Bitboard) History() []env.Action {
+	return history(b.Moves(), b.ActionMark)
+}
+
+func (b *Bitboard) Winner() int {
+	return b.status
+}
+
+func (b *Bitboard) Reward(player int) float64 {
+	return env.TerminalReward(b.status, player)
+}
+
+func (b *Bitboard) Key() string {
+	return strconv.FormatInt(b.ID(), 10)
+}
+
+// FormatAction formats a as Board.FormatAction does.
+func (b *Bitboard) FormatAction(a env.Action) string {
+	return b.Board().FormatAction(a)
+}
+
+// ParseAction parses a move as Board.ParseAction does.
+func (b *Bitboard) ParseAction(s string) (env.Action, error) {
+	return b.Board().ParseAction(s)
+}
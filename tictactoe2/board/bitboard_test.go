@@ -0,0 +1,112 @@
+package board_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// Test that Bitboard plays random games exactly as Board does, including
+// undoing and redoing moves
+func TestBitboardMatchesBoard(t *testing.T) {
+	configs := []board.Config{
+		{Width: 4, Height: 4, K: 3},
+		{Width: 5, Height: 4, K: 4, Rules: board.Misere},
+		{Width: 7, Height: 5, K: 4},
+	}
+	for r := board.Standard; r <= board.NoDraws; r++ {
+		cfg := board.TicTacToe
+		cfg.Rules = r
+		configs = append(configs, cfg)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for _, cfg := range configs {
+		for i := 0; i < 200; i++ {
+			var b env.State = board.NewBoardConfig(cfg, 1+i%2)
+			var bb env.State = board.NewBitboardConfig(cfg, 1+i%2)
+			for {
+				if b.Key() != bb.Key() || b.Winner() != bb.Winner() || !slices.Equal(b.Actions(), bb.Actions()) {
+					t.Fatalf("%v: board %s winner %d, bitboard %s winner %d", cfg, b.Key(), b.Winner(), bb.Key(), bb.Winner())
+				}
+				if b.Winner() != 0 {
+					break
+				}
+				actions := b.Actions()
+				a := actions[rng.Intn(len(actions))]
+				b, _ = b.Apply(a)
+				bb, _ = bb.Apply(a)
+			}
+
+			full, played := bb.(*board.Bitboard), len(b.(*board.Board).Moves())
+			undo := full.Clone()
+			for undo.UndoMove() {
+			}
+			if undo.ID() != board.NewBitboardConfig(cfg, 1+i%2).ID() {
+				t.Fatalf("%v: undoing every move left %s", cfg, undo.Key())
+			}
+			for undo.RedoMove() {
+			}
+			if undo.Key() != full.Key() || undo.Winner() != full.Winner() || len(undo.Moves()) != played {
+				t.Fatalf("%v: redoing every move gave %s, want %s", cfg, undo.Key(), full.Key())
+			}
+			if !slices.Equal(full.Get(), b.(*board.Board).Get()) || !slices.Equal(full.Moves(), b.(*board.Board).Moves()) {
+				t.Fatalf("%v: cells or moves differ", cfg)
+			}
+		}
+	}
+}
+
+var backends = []struct {
+	name string
+	env  env.Environment
+}{
+	{"Board", board.Environment{Config: board.TicTacToe}},
+	{"Bitboard", board.Environment{Config: board.TicTacToe, Bitboard: true}},
+}
+
+// Benchmark random playouts, which only make moves and check for wins
+func BenchmarkPlayout(b *testing.B) {
+	for _, backend := range backends {
+		b.Run(backend.name, func(b *testing.B) {
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < b.N; i++ {
+				s := backend.env.NewState(1)
+				for s.Winner() == 0 {
+					actions := s.Actions()
+					s, _ = s.Apply(actions[rng.Intn(len(actions))])
+				}
+			}
+		})
+	}
+}
+
+// Benchmark solving the empty board with a new MinimaxPlayer
+func BenchmarkMinimax(b *testing.B) {
+	for _, backend := range backends {
+		b.Run(backend.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				player.NewMinimaxPlayer(1).OptimalMoves(backend.env.NewState(1))
+			}
+		})
+	}
+}
+
+// Benchmark training games of a learner against a random player, as in the
+// first phase of tt train; each iteration is one game
+func BenchmarkTraining(b *testing.B) {
+	for _, backend := range backends {
+		b.Run(backend.name, func(b *testing.B) {
+			learner := player.NewLearnerPlayer(1, 0.2, 0.1, "learner")
+			opponent := player.NewRandomPlayer(2)
+			for i := 0; i < b.N; i++ {
+				game.NewGameState(backend.env.NewState(1), learner, opponent, true).Play()
+			}
+		})
+	}
+}
@@ -1,31 +1,84 @@
 package board
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Action struct {
-	X      int // X coordinate (0-2)
-	Y      int // Y coordinate (0-2)
+	X      int // X coordinate (column, 0 to width-1)
+	Y      int // Y coordinate (row, 0 to height-1)
 	Player int // Player number (1 or 2
+	Mark   int // Mark placed, 1 - X 2 - O. The player's own mark unless the rules say otherwise
 }
 
 type Board struct {
-	board  [9]int
+	cfg    Config
+	geo    *geometry // Lines of the configuration, shared between copies
+	board  []int
 	start  int
 	player int
+	empty  int      // Number of empty cells
+	status int      // Result of CheckWin, updated by MakeMove
+	cells  int64    // Base-3 encoding of board, the first part of ID
+	moves  []Action // Moves played, for UndoMove
+	undone []Action // Moves taken back, latest last, for RedoMove
 }
 
 func NewBoard(start int) *Board {
+	return NewBoardConfig(TicTacToe, start)
+}
+
+// NewBoardConfig creates an empty board for cfg. cfg must be valid.
+func NewBoardConfig(cfg Config, start int) *Board {
 	return &Board{
+		cfg:    cfg,
+		geo:    geometryFor(cfg),
+		board:  make([]int, cfg.Cells()),
 		start:  start,
 		player: start,
+		empty:  cfg.Cells(),
 	}
 }
 
-func (b *Board) Get() [9]int {
-	var newBoard [9]int
-	for i := 0; i < 9; i++ {
-		newBoard[i] = b.board[i]
+// Clone returns an independent copy of the board, with its own moves to
+// undo and redo.
+func (b *Board) Clone() *Board {
+	c := *b
+	c.board = b.Get()
+	c.moves = append([]Action(nil), b.moves...)
+	c.undone = append([]Action(nil), b.undone...)
+	return &c
+}
+
+func (b *Board) Config() Config {
+	return b.cfg
+}
+
+// ConfigKey identifies the configuration, for env.Configured.
+func (b *Board) ConfigKey() string {
+	return b.cfg.String()
+}
+
+func (b *Board) Width() int {
+	return b.cfg.Width
+}
+
+func (b *Board) Height() int {
+	return b.cfg.Height
+}
+
+// Index returns the cell index of (x, y), or -1 if it is off the board.
+func (b *Board) Index(x, y int) int {
+	if x < 0 || x >= b.cfg.Width || y < 0 || y >= b.cfg.Height {
+		return -1
 	}
+	return x + b.cfg.Width*y
+}
+
+func (b *Board) Get() []int {
+	newBoard := make([]int, len(b.board))
+	copy(newBoard, b.board)
 	return newBoard
 }
 
@@ -35,22 +88,29 @@ func (b *Board) GetStart() int {
 
 func (b *Board) GetPossibleMoves() []Action {
 	var actions []Action
-	for i := 0; i < 9; i++ {
+	for i := 0; i < len(b.board); i++ {
 		if b.board[i] == 0 { // Cell is empty
-			x := i % 3
-			y := i / 3
-			actions = append(actions, Action{X: x, Y: y, Player: b.player})
+			x := i % b.cfg.Width
+			y := i / b.cfg.Width
+			for _, mark := range b.cfg.Rules.marks(b.player) {
+				actions = append(actions, Action{X: x, Y: y, Player: b.player, Mark: mark})
+			}
 		}
 	}
 	return actions
 }
 
-func (b *Board) CalcPossibleMoves(brd [9]int) []Action {
+// DefaultMark returns the mark player places when the move does not say.
+func (b *Board) DefaultMark(player int) int {
+	return b.cfg.Rules.defaultMark(player)
+}
+
+func (b *Board) CalcPossibleMoves(brd []int) []Action {
 	var actions []Action
-	for i := 0; i < 9; i++ {
+	for i := 0; i < len(brd); i++ {
 		if brd[i] == 0 { // Cell is empty
-			x := i % 3
-			y := i / 3
+			x := i % b.cfg.Width
+			y := i / b.cfg.Width
 			actions = append(actions, Action{X: x, Y: y})
 		}
 	}
@@ -61,13 +121,15 @@ func (b *Board) NextPlayer() int {
 	return b.player
 }
 
-func (b *Board) CalcID(brd [9]int, start int, player int) int64 {
+// CalcID returns a unique ID for the board state. Boards of more than
+// MaxIDCells cells overflow the ID.
+func (b *Board) CalcID(brd []int, start int, player int) int64 {
 	// Calculate a unique ID for the board state
 	// This is a base-3 encoding of the board, with additional bits for start and player
 	var id int64 = 0
 
 	// Encode board (base-3)
-	for i := 0; i < 9; i++ {
+	for i := 0; i < len(brd); i++ {
 		id = id*3 + int64(brd[i])
 	}
 
@@ -83,54 +145,106 @@ func (b *Board) CalcID(brd [9]int, start int, player int) int64 {
 	return id
 }
 
+// ID returns CalcID of the board, kept up to date by every move.
 func (b *Board) ID() int64 {
-	return b.CalcID(b.board, b.start, b.player)
+	return (b.cells*2+int64(b.start-1))*2 + int64(b.player-1)
 }
 
 // TryMove attempts to place player's mark at (x, y).
 // Returns true if move succeeded, false if the cell was not empty.
-func (b *Board) TryMove(brd [9]int, x, y, player int) ([9]int, bool) {
-	ret := [9]int{}
-	idx := x + 3*y
-	if idx < 0 || idx >= 9 {
-		return ret, false // Out of bounds
+func (b *Board) TryMove(brd []int, x, y, player int) ([]int, bool) {
+	idx := b.Index(x, y)
+	if idx < 0 {
+		return nil, false // Out of bounds
 	}
 	if brd[idx] != 0 {
-		return ret, false // Cell already taken
+		return nil, false // Cell already taken
 	}
 
-	var newBoard [9]int
-	for i := 0; i < 9; i++ {
-		newBoard[i] = brd[i]
-	}
+	newBoard := make([]int, len(brd))
+	copy(newBoard, brd)
 
 	newBoard[idx] = player
 	return newBoard, true
 }
 
-// TryMove attempts to place player's mark at (x, y).
-// Returns true if move succeeded, false if the cell was not empty.
+// MakeMove places player's default mark at (x, y) and updates the game
+// status using only the lines through (x, y).
+// Returns true if move succeeded, false if the move was not legal.
 func (b *Board) MakeMove(x, y, player int) bool {
+	return b.MakeMoveMark(x, y, player, b.DefaultMark(player))
+}
+
+// MakeMoveMark is MakeMove for rules that let the player choose the mark.
+// A new move clears the moves RedoMove would replay.
+func (b *Board) MakeMoveMark(x, y, player, mark int) bool {
+	if !b.play(Action{X: x, Y: y, Player: player, Mark: mark}) {
+		return false
+	}
+	b.undone = b.undone[:0]
+	return true
+}
 
-	if b.player != player {
+// play makes move and pushes it on the move stack.
+func (b *Board) play(move Action) bool {
+	if b.player != move.Player || b.status != 0 || !b.allowed(move.Player, move.Mark) {
 		return false
 	}
 
-	idx := x + 3*y
-	if idx < 0 || idx >= 9 {
+	idx := b.Index(move.X, move.Y)
+	if idx < 0 {
 		return false // Out of bounds
 	}
 	if b.board[idx] != 0 {
 		return false // Cell already taken
 	}
 
-	b.board[idx] = player
+	b.board[idx] = move.Mark
+	b.cells += int64(move.Mark) * b.geo.place[idx]
+	b.empty--
+	b.status = b.calcWinAt(b.board, idx, move.Player, b.empty)
+	b.moves = append(b.moves, move)
 
 	// toggle the next player
 	b.player = 3 - b.player
 	return true
 }
 
+// UndoMove takes back the last move. It returns false if no move has been
+// made since the board was created.
+func (b *Board) UndoMove() bool {
+	if len(b.moves) == 0 {
+		return false
+	}
+	move := b.moves[len(b.moves)-1]
+	b.moves = b.moves[:len(b.moves)-1]
+
+	idx := b.Index(move.X, move.Y)
+	b.board[idx] = 0
+	b.cells -= int64(move.Mark) * b.geo.place[idx]
+	b.empty++
+	b.status = 0 // moves are only made while the game is being played
+	b.player = move.Player
+	b.undone = append(b.undone, move)
+	return true
+}
+
+// RedoMove replays the last move taken back by UndoMove. It returns false
+// if there is none, or a move has been made since.
+func (b *Board) RedoMove() bool {
+	if len(b.undone) == 0 {
+		return false
+	}
+	move := b.undone[len(b.undone)-1]
+	b.undone = b.undone[:len(b.undone)-1]
+	return b.play(move)
+}
+
+// Moves returns the moves made since the board was created, in the order they were made.
+func (b *Board) Moves() []Action {
+	return append([]Action(nil), b.moves...)
+}
+
 // CheckWin returns:
 //
 //	0 if the game continues (no win, no draw),
@@ -138,36 +252,97 @@ func (b *Board) MakeMove(x, y, player int) bool {
 //	2 if player 2 wins,
 //	3 if the game is a draw
 func (b *Board) CheckWin() int {
-	return b.CalcWin(b.board)
+	return b.status
+}
+
+// allowed reports whether the rules let player place mark.
+func (b *Board) allowed(player, mark int) bool {
+	return b.cfg.Rules.allows(player, mark)
+}
+
+// mover returns the player who made the last move on brd, from the number
+// of marks and the start player.
+func (b *Board) mover(brd []int) int {
+	marks := 0
+	for _, cell := range brd {
+		if cell != 0 {
+			marks++
+		}
+	}
+	if marks%2 == 1 {
+		return b.start
+	}
+	return 3 - b.start
+}
+
+// CalcWin returns the status of brd as CheckWin does, checking every line.
+func (b *Board) CalcWin(brd []int) int {
+	return b.cfg.Rules.outcome(b.lineMark(brd), b.mover(brd), !hasEmpty(brd))
+}
+
+// lineMark returns the mark of a completed line on brd, or 0 if there is none.
+func (b *Board) lineMark(brd []int) int {
+	for _, line := range b.geo.lines {
+		first := brd[line[0]]
+		if first == 0 {
+			continue
+		}
+		won := true
+		for _, idx := range line[1:] {
+			if brd[idx] != first {
+				won = false
+				break
+			}
+		}
+		if won {
+			return first
+		}
+	}
+	return 0
 }
 
-func (b *Board) CalcWin(board [9]int) int {
-	winPatterns := [8][3]int{
-		{0, 1, 2}, // rows
-		{3, 4, 5},
-		{6, 7, 8},
-		{0, 3, 6}, // columns
-		{1, 4, 7},
-		{2, 5, 8},
-		{0, 4, 8}, // diagonals
-		{2, 4, 6},
+func hasEmpty(brd []int) bool {
+	for i := 0; i < len(brd); i++ {
+		if brd[i] == 0 {
+			return true // Game continues, empty spots left
+		}
 	}
+	return false
+}
 
-	for _, pattern := range winPatterns {
-		a, bIdx, c := pattern[0], pattern[1], pattern[2]
-		if board[a] != 0 && board[a] == board[bIdx] && board[bIdx] == board[c] {
-			return board[a]
+// CalcWinAt returns the status of brd as CheckWin does, given that brd was
+// not finished before a mark was placed at (x, y). Only the lines through
+// (x, y) are checked.
+func (b *Board) CalcWinAt(brd []int, x, y int) int {
+	empty := 0
+	for _, cell := range brd {
+		if cell == 0 {
+			empty++
 		}
 	}
+	return b.calcWinAt(brd, b.Index(x, y), b.mover(brd), empty)
+}
 
-	// Check for draw (no zeros left)
-	for i := 0; i < 9; i++ {
-		if board[i] == 0 {
-			return 0 // Game continues, empty spots left
+// calcWinAt scores brd after mover placed a mark at idx, leaving empty
+// cells free.
+func (b *Board) calcWinAt(brd []int, idx int, mover int, empty int) int {
+	mark := brd[idx]
+	lineMark := 0
+	for _, line := range b.geo.cellLines[idx] {
+		won := true
+		for _, i := range line {
+			if brd[i] != mark {
+				won = false
+				break
+			}
+		}
+		if won {
+			lineMark = mark
+			break
 		}
 	}
 
-	return 3 // Draw
+	return b.cfg.Rules.outcome(lineMark, mover, empty == 0)
 }
 
 // Print prints the board in a human-readable format.
@@ -178,10 +353,18 @@ func (b *Board) Print() {
 	fmt.Println("Next Player:", b.player)
 }
 
-func (b *Board) PrintBoard(brd [9]int) {
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			idx := i*3 + j
+// PrintBoard prints brd with the row numbers and column letters of
+// CellName, when the board is narrow enough to have them.
+func (b *Board) PrintBoard(brd []int) {
+	w, h := b.cfg.Width, b.cfg.Height
+	labels := CellName(b.cfg, 0, 0) != ""
+	margin := len(fmt.Sprint(h)) + 1
+	for i := 0; i < h; i++ {
+		if labels {
+			fmt.Printf("%*d ", margin-1, h-i)
+		}
+		for j := 0; j < w; j++ {
+			idx := i*w + j
 			switch brd[idx] {
 			case 0:
 				fmt.Print(" . ")
@@ -190,13 +373,23 @@ func (b *Board) PrintBoard(brd [9]int) {
 			case 2:
 				fmt.Print(" O ")
 			}
-			if j < 2 {
+			if j < w-1 {
 				fmt.Print("|")
 			}
 		}
 		fmt.Println()
-		if i < 2 {
-			fmt.Println("-----------")
+		if i < h-1 {
+			if labels {
+				fmt.Print(strings.Repeat(" ", margin))
+			}
+			fmt.Println(strings.Repeat("-", 4*w-1))
+		}
+	}
+	if labels {
+		fmt.Print(strings.Repeat(" ", margin))
+		for j := 0; j < w; j++ {
+			fmt.Printf(" %c  ", 'a'+j)
 		}
+		fmt.Println()
 	}
 }
@@ -0,0 +1,274 @@
+package board
+
+import "testing"
+
+// Test that MakeMove's incremental status agrees with CalcWin
+func TestMakeMoveStatus(t *testing.T) {
+	tests := []struct {
+		cfg   Config
+		moves [][2]int // x, y in play order, X first
+		want  int
+	}{
+		{TicTacToe, [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}}, 1},                                         // Row win
+		{TicTacToe, [][2]int{{0, 0}, {2, 0}, {1, 0}, {1, 1}, {2, 2}, {0, 2}}, 2},                                 // Anti-diagonal win
+		{TicTacToe, [][2]int{{0, 0}, {1, 1}}, 0},                                                                 // Game continues
+		{TicTacToe, [][2]int{{0, 0}, {1, 0}, {2, 0}, {1, 1}, {0, 1}, {0, 2}, {2, 1}, {2, 2}, {1, 2}}, 3},         // Draw
+		{Config{Width: 4, Height: 4, K: 3}, [][2]int{{1, 1}, {0, 0}, {2, 2}, {3, 0}, {3, 3}}, 1},                 // Diagonal win off the main corner
+		{Config{Width: 4, Height: 4, K: 4}, [][2]int{{1, 1}, {0, 0}, {2, 2}, {3, 0}, {3, 3}}, 0},                 // Three is not enough for k=4
+		{Config{Width: 5, Height: 3, K: 4}, [][2]int{{1, 2}, {0, 0}, {2, 2}, {1, 0}, {3, 2}, {2, 0}, {4, 2}}, 1}, // Row win on a wide board
+	}
+	for _, tt := range tests {
+		b := NewBoardConfig(tt.cfg, 1)
+		player := 1
+		for _, m := range tt.moves {
+			if !b.MakeMove(m[0], m[1], player) {
+				t.Fatalf("%v: move %v rejected", tt.cfg, m)
+			}
+			player = 3 - player
+		}
+		if got := b.CheckWin(); got != tt.want {
+			t.Errorf("%v %v: CheckWin() = %d; want %d", tt.cfg, tt.moves, got, tt.want)
+		}
+		if got := b.CalcWin(b.Get()); got != tt.want {
+			t.Errorf("%v %v: CalcWin() = %d; want %d", tt.cfg, tt.moves, got, tt.want)
+		}
+	}
+}
+
+// Test that a finished game rejects further moves
+func TestMakeMoveAfterWin(t *testing.T) {
+	b := NewBoard(1)
+	for i, m := range [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}} {
+		b.MakeMove(m[0], m[1], 1+i%2)
+	}
+	if b.MakeMove(2, 2, 2) {
+		t.Errorf("MakeMove succeeded after X won")
+	}
+}
+
+// Test that CalcID matches the original 3x3 encoding
+func TestCalcID(t *testing.T) {
+	b := NewBoard(1)
+	brd := []int{1, 0, 0, 0, 2, 0, 0, 0, 0}
+	want := int64(((1*6561+2*81)*2+0)*2 + 1)
+	if got := b.CalcID(brd, 1, 2); got != want {
+		t.Errorf("CalcID(%v, 1, 2) = %d; want %d", brd, got, want)
+	}
+}
+
+// Test that the largest board Validate accepts has no ID overflow
+func TestMaxIDCells(t *testing.T) {
+	cfg := Config{Width: MaxIDCells, Height: 1, K: MaxIDCells, Rules: Wild}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Config{Width: MaxIDCells + 1, Height: 1, K: 3}).Validate(); err == nil {
+		t.Errorf("%d cells accepted", MaxIDCells+1)
+	}
+
+	// the largest ID is O, the largest digit, in every cell with O having
+	// started and to move, which is the full board after an even number of
+	// moves under wild rules
+	b := NewBoardConfig(cfg, 2)
+	for x := 0; x < MaxIDCells; x++ {
+		if !b.Play(b.ActionMark(x, 0, 2)) {
+			t.Fatalf("O at %d rejected", x)
+		}
+	}
+	if id := b.ID(); id != 4*pow3(MaxIDCells)-1 || id <= 0 {
+		t.Errorf("largest ID = %d, want 4*3^%d-1 and positive", id, MaxIDCells)
+	}
+}
+
+func pow3(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 3
+	}
+	return p
+}
+
+// Test the outcome of completing a line under each rule variant
+func TestRulesOutcome(t *testing.T) {
+	tests := []struct {
+		rules Rules
+		moves [][3]int // x, y, mark in play order, X first
+		want  int
+	}{
+		{Misere, [][3]int{{0, 0, 1}, {0, 1, 2}, {1, 0, 1}, {1, 1, 2}, {2, 0, 1}}, 2},                                              // X completes a line and loses
+		{Wild, [][3]int{{0, 0, 2}, {0, 1, 1}, {1, 0, 2}, {1, 1, 1}, {2, 1, 1}}, 1},                                                // X completes a line of X's placed by both
+		{Wild, [][3]int{{0, 0, 2}, {1, 0, 2}, {2, 0, 2}}, 1},                                                                      // X completes a line of O's and wins
+		{Notakto, [][3]int{{0, 0, 1}, {1, 0, 1}, {2, 2, 1}, {2, 0, 1}}, 1},                                                        // O completes a line and loses
+		{NoDraws, [][3]int{{0, 0, 1}, {1, 0, 2}, {2, 0, 1}, {1, 1, 2}, {0, 1, 1}, {0, 2, 2}, {2, 1, 1}, {2, 2, 2}, {1, 2, 1}}, 2}, // Draw goes to O
+	}
+	for _, tt := range tests {
+		cfg := TicTacToe
+		cfg.Rules = tt.rules
+		b := NewBoardConfig(cfg, 1)
+		for _, m := range tt.moves {
+			if !b.MakeMoveMark(m[0], m[1], b.NextPlayer(), m[2]) {
+				t.Fatalf("%v: move %v rejected", tt.rules, m)
+			}
+		}
+		if got := b.CheckWin(); got != tt.want {
+			t.Errorf("%v: CheckWin() = %d; want %d", tt.rules, got, tt.want)
+		}
+		if got := b.CalcWin(b.Get()); got != tt.want {
+			t.Errorf("%v: CalcWin() = %d; want %d", tt.rules, got, tt.want)
+		}
+	}
+}
+
+// Test that players may only place the marks their rules allow
+func TestRulesMarks(t *testing.T) {
+	cfg := TicTacToe
+	cfg.Rules = Notakto
+	b := NewBoardConfig(cfg, 1)
+	if b.MakeMoveMark(0, 0, 1, 2) {
+		t.Errorf("Notakto allowed an O")
+	}
+	if !b.MakeMove(0, 0, 1) || !b.MakeMove(1, 1, 2) || b.Get()[4] != 1 {
+		t.Errorf("Notakto moves should both place X, got %v", b.Get())
+	}
+	if b.MakeMoveMark(2, 2, 1, 2) {
+		t.Errorf("Notakto allowed an O")
+	}
+}
+
+// Test the notations ParseAction accepts
+func TestParseAction(t *testing.T) {
+	b := NewBoard(1)
+	b.MakeMove(1, 1, 1)
+	tests := []struct {
+		text string
+		x, y int
+		ok   bool
+	}{
+		{"2 0", 2, 0, true},
+		{"a1", 0, 2, true},
+		{"C3", 2, 0, true},
+		{"7", 0, 0, true},
+		{"3", 2, 2, true},
+		{"b2", 0, 0, false}, // taken
+		{"5", 0, 0, false},  // taken
+		{"d1", 0, 0, false},
+		{"a4", 0, 0, false},
+		{"0", 0, 0, false},
+		{"1 2 3", 0, 0, false},
+	}
+	for _, tt := range tests {
+		a, err := b.ParseAction(tt.text)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: error %v", tt.text, err)
+			continue
+		}
+		if x, y := b.Coords(a); tt.ok && (x != tt.x || y != tt.y) {
+			t.Errorf("%q: got (%d, %d); want (%d, %d)", tt.text, x, y, tt.x, tt.y)
+		}
+	}
+
+	cfg := TicTacToe
+	cfg.Rules = Wild
+	w := NewBoardConfig(cfg, 1)
+	if a, err := w.ParseAction("b2 o"); err != nil || a != w.ActionMark(1, 1, 2) {
+		t.Errorf("wild \"b2 o\" = %v, %v", a, err)
+	}
+}
+
+// Test that UndoMove and RedoMove restore the cells, ID and status
+func TestUndoRedo(t *testing.T) {
+	b := NewBoard(2)
+	var ids []int64
+	var statuses []int
+	for _, m := range [][2]int{{1, 1}, {0, 0}, {2, 2}, {0, 2}, {0, 1}, {2, 1}, {1, 0}, {1, 2}, {2, 0}} {
+		ids = append(ids, b.ID())
+		statuses = append(statuses, b.CheckWin())
+		if !b.MakeMove(m[0], m[1], b.NextPlayer()) {
+			break
+		}
+		if b.ID() != b.CalcID(b.Get(), b.GetStart(), b.NextPlayer()) {
+			t.Fatalf("after %v: ID %d; CalcID %d", m, b.ID(), b.CalcID(b.Get(), b.GetStart(), b.NextPlayer()))
+		}
+	}
+	final, played := b.Clone(), len(b.Moves())
+	if final.CheckWin() == 0 {
+		t.Fatal("the game should be over")
+	}
+
+	for i := played - 1; i >= 0; i-- {
+		if !b.UndoMove() {
+			t.Fatalf("undo %d failed", i)
+		}
+		if b.ID() != ids[i] || b.CheckWin() != statuses[i] {
+			t.Errorf("undo %d: ID %d status %d; want %d %d", i, b.ID(), b.CheckWin(), ids[i], statuses[i])
+		}
+	}
+	if b.UndoMove() {
+		t.Error("undo on an empty board succeeded")
+	}
+
+	for b.RedoMove() {
+	}
+	if b.ID() != final.ID() || b.CheckWin() != final.CheckWin() || len(b.Moves()) != played {
+		t.Errorf("redo: ID %d status %d; want %d %d", b.ID(), b.CheckWin(), final.ID(), final.CheckWin())
+	}
+
+	b.UndoMove()
+	b.UndoMove()
+	free := b.GetPossibleMoves()[1]
+	if !b.MakeMove(free.X, free.Y, b.NextPlayer()) || b.RedoMove() {
+		t.Error("redo after a new move succeeded")
+	}
+}
+
+// Test that Parse reads what String writes, and rejects impossible positions
+func TestParse(t *testing.T) {
+	b := NewBoard(1)
+	for _, m := range [][2]int{{0, 0}, {2, 0}, {1, 1}, {2, 2}} {
+		b.MakeMove(m[0], m[1], b.NextPlayer())
+	}
+	if got := b.String(); got != "X.O/.X./..O x" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for r := Standard; r <= NoDraws; r++ {
+		cfg := TicTacToe
+		cfg.Rules = r
+		for seed := 0; seed < 100; seed++ {
+			b := NewBoardConfig(cfg, 1+seed%2)
+			for n := seed; b.CheckWin() == 0 && n > 0; n /= 3 {
+				moves := b.GetPossibleMoves()
+				m := moves[(seed+n)%len(moves)]
+				b.MakeMoveMark(m.X, m.Y, m.Player, m.Mark)
+			}
+			parsed, err := Parse(cfg, b.String())
+			if err != nil {
+				t.Fatalf("%v %q: %v", r, b.String(), err)
+			}
+			if parsed.ID() != b.ID() || parsed.CheckWin() != b.CheckWin() || len(parsed.Moves()) != len(b.Moves()) {
+				t.Errorf("%v %q: parsed %q, status %d, want %d", r, b.String(), parsed.String(), parsed.CheckWin(), b.CheckWin())
+			}
+		}
+	}
+
+	for _, bad := range []string{
+		"X.O/.X./..O",         // no player to move
+		"XX./.../... x",       // X has two marks more than O
+		"X.O/.X. x",           // missing row
+		"X.O/.X./..O. x",      // long row
+		"X.Q/.X./..O x",       // unknown mark
+		"X.O/.X./..O x o",     // O cannot have started
+		"XXX/OOO/X.. o",       // X won before the last move
+		"XXX/OO./O.. x",       // X won before O's last move
+		"X.O/.X./..O z",       // unknown player
+		"X.O/.X./..O x x x",   // too many fields
+		"XXX/OO./... o extra", // not a player
+	} {
+		if _, err := Parse(TicTacToe, bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+	if b, err := Parse(TicTacToe, "XXX/OO./... o"); err != nil || b.CheckWin() != 1 {
+		t.Errorf("a won game should parse: %v", err)
+	}
+}
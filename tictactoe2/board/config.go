@@ -0,0 +1,124 @@
+package board
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxIDCells is the largest number of cells whose states fit in CalcID:
+// the largest ID, 4 * 3^cells - 1, must fit in an int64.
+const MaxIDCells = 38
+
+// Config describes an m,n,k-game: a Width x Height grid where K marks in a
+// row, column or diagonal complete a line. Rules decide what completing a
+// line means; under Standard rules the first player to do it wins.
+type Config struct {
+	Width  int
+	Height int
+	K      int
+	Rules  Rules
+}
+
+// TicTacToe is the classic 3x3, three in a row game.
+var TicTacToe = Config{Width: 3, Height: 3, K: 3}
+
+func (c Config) Cells() int {
+	return c.Width * c.Height
+}
+
+// Validate returns an error if no board can be built for c.
+func (c Config) Validate() error {
+	if c.Width < 1 || c.Height < 1 {
+		return fmt.Errorf("board must be at least 1x1, got %dx%d", c.Width, c.Height)
+	}
+	if c.K < 1 || (c.K > c.Width && c.K > c.Height) {
+		return fmt.Errorf("k=%d in a row does not fit on a %dx%d board", c.K, c.Width, c.Height)
+	}
+	if c.Rules < Standard || c.Rules > NoDraws {
+		return fmt.Errorf("unknown rules %d", int(c.Rules))
+	}
+	if c.Cells() > MaxIDCells {
+		return fmt.Errorf("%dx%d board has more than %d cells", c.Width, c.Height, MaxIDCells)
+	}
+	return nil
+}
+
+func (c Config) String() string {
+	if c.Rules != Standard {
+		return fmt.Sprintf("%dx%d k=%d %s", c.Width, c.Height, c.K, c.Rules)
+	}
+	return fmt.Sprintf("%dx%d k=%d", c.Width, c.Height, c.K)
+}
+
+// geometry holds every winning line of a configuration.
+type geometry struct {
+	lines      [][]int    // Every K-cell segment of a row, column or diagonal
+	cellLines  [][][]int  // Lines through each cell
+	place      []int64    // Place value of each cell in the base-3 ID
+	cellMasks  [][]uint64 // Lines through each cell as bit masks, for Bitboard
+	full       uint64     // Mask of every cell
+	symmetries [][]int    // Cell maps of the rotations and reflections
+}
+
+var (
+	geometriesMu sync.Mutex
+	geometries   = map[Config]*geometry{}
+)
+
+// geometryFor returns the lines of cfg, computing them on first use.
+func geometryFor(cfg Config) *geometry {
+	cfg.Rules = Standard // lines do not depend on the rules
+
+	geometriesMu.Lock()
+	defer geometriesMu.Unlock()
+
+	if g, ok := geometries[cfg]; ok {
+		return g
+	}
+
+	g := &geometry{
+		cellLines:  make([][][]int, cfg.Cells()),
+		place:      make([]int64, cfg.Cells()),
+		cellMasks:  make([][]uint64, cfg.Cells()),
+		full:       1<<cfg.Cells() - 1,
+		symmetries: symmetries(cfg),
+	}
+	var place int64 = 1
+	for idx := cfg.Cells() - 1; idx >= 0; idx-- {
+		g.place[idx] = place
+		place *= 3
+	}
+	directions := [4][2]int{
+		{1, 0}, // rows
+		{0, 1}, // columns
+		{1, 1}, // diagonals
+		{1, -1},
+	}
+	for y := 0; y < cfg.Height; y++ {
+		for x := 0; x < cfg.Width; x++ {
+			for _, d := range directions {
+				endX := x + d[0]*(cfg.K-1)
+				endY := y + d[1]*(cfg.K-1)
+				if endX < 0 || endX >= cfg.Width || endY < 0 || endY >= cfg.Height {
+					continue
+				}
+				line := make([]int, cfg.K)
+				for i := 0; i < cfg.K; i++ {
+					line[i] = (x + d[0]*i) + cfg.Width*(y+d[1]*i)
+				}
+				g.lines = append(g.lines, line)
+				var mask uint64
+				for _, idx := range line {
+					g.cellLines[idx] = append(g.cellLines[idx], line)
+					mask |= 1 << idx
+				}
+				for _, idx := range line {
+					g.cellMasks[idx] = append(g.cellMasks[idx], mask)
+				}
+			}
+		}
+	}
+
+	geometries[cfg] = g
+	return g
+}
@@ -0,0 +1,159 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// String writes the position as Parse reads it: the rows from the top,
+// separated by "/", with X, O and "." for an empty cell, then the player to
+// move, x or o. For example "X.O/.X./..O x".
+func (b *Board) String() string {
+	var sb strings.Builder
+	for i, cell := range b.board {
+		if i > 0 && i%b.cfg.Width == 0 {
+			sb.WriteByte('/')
+		}
+		sb.WriteByte(".XO"[cell])
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(playerNames[b.player])
+	return sb.String()
+}
+
+// String writes the position as Board.String does.
+func (b *Bitboard) String() string {
+	return b.Board().String()
+}
+
+var playerNames = []string{"", "x", "o"}
+
+// parsePlayer parses a player written as x or o.
+func parsePlayer(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "x":
+		return 1, nil
+	case "o":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("player %q must be x or o", s)
+}
+
+// Parse reads a position of a board of cfg written as String writes it,
+// optionally followed by the player who started, which must agree with the
+// number of marks and the player to move. The marks must be ones the
+// players could have placed taking turns under cfg's rules, and the game
+// must not have been over before the last move. The board's move stack
+// holds one order in which the marks could have been placed.
+func Parse(cfg Config, s string) (*Board, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, errors.New("a position is the rows separated by /, the player to move and optionally the player who started, e.g. \"X.O/.X./..O x\"")
+	}
+
+	rows := strings.Split(fields[0], "/")
+	if len(rows) != cfg.Height {
+		return nil, fmt.Errorf("%d rows, the board has %d", len(rows), cfg.Height)
+	}
+	brd := make([]int, 0, cfg.Cells())
+	for y, row := range rows {
+		if len(row) != cfg.Width {
+			return nil, fmt.Errorf("row %d has %d cells, the board has %d columns", y+1, len(row), cfg.Width)
+		}
+		for _, c := range row {
+			mark := strings.IndexRune(".XO", c)
+			if mark < 0 {
+				return nil, fmt.Errorf("invalid cell %q, use X, O or .", c)
+			}
+			brd = append(brd, mark)
+		}
+	}
+
+	toMove, err := parsePlayer(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	cells := [3][]int{} // Cells by mark
+	for idx, mark := range brd {
+		cells[mark] = append(cells[mark], idx)
+	}
+	marks := len(cells[1]) + len(cells[2])
+	start := toMove
+	if marks%2 == 1 {
+		start = 3 - toMove
+	}
+	if len(fields) == 3 {
+		given, err := parsePlayer(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		if given != start {
+			return nil, fmt.Errorf("with %d marks and %s to move, %s must have started", marks, playerNames[toMove], playerNames[start])
+		}
+	}
+
+	b := NewBoardConfig(cfg, start)
+	switch cfg.Rules {
+	case Wild:
+	case Notakto:
+		if len(cells[2]) > 0 {
+			return nil, errors.New("only X is played under notakto rules")
+		}
+	default:
+		if len(cells[start]) != (marks+1)/2 || len(cells[3-start]) != marks/2 {
+			return nil, fmt.Errorf("X has %d marks and O %d, which is impossible with %s to move",
+				len(cells[1]), len(cells[2]), playerNames[toMove])
+		}
+	}
+
+	// the last move must be one that a game still being played allowed
+	last := -1
+	if marks > 0 {
+		over := b.lineMark(brd) != 0
+		for idx, mark := range brd {
+			if mark == 0 || !cfg.Rules.allows(3-toMove, mark) {
+				continue
+			}
+			if over {
+				before := append([]int{}, brd...)
+				before[idx] = 0
+				if b.lineMark(before) != 0 {
+					continue
+				}
+			}
+			last = idx
+			break
+		}
+		if last < 0 {
+			return nil, errors.New("the game was over before the last move")
+		}
+	}
+
+	// place the other marks in turn, each player taking the cells of the
+	// marks they may place, then the last one
+	queues := [3][]int{} // Cells left to place, by the mark the player places, or 0 for any
+	for idx, mark := range brd {
+		if mark == 0 || idx == last {
+			continue
+		}
+		if cfg.Rules == Wild {
+			mark = 0
+		}
+		queues[mark] = append(queues[mark], idx)
+	}
+	for i := 0; i < marks; i++ {
+		idx := last
+		if i < marks-1 {
+			q := 0
+			if cfg.Rules != Wild {
+				q = cfg.Rules.defaultMark(b.player)
+			}
+			idx, queues[q] = queues[q][0], queues[q][1:]
+		}
+		if !b.MakeMoveMark(idx%cfg.Width, idx/cfg.Width, b.player, brd[idx]) {
+			return nil, fmt.Errorf("%s cannot place %c at %s", playerNames[b.player], ".XO"[brd[idx]], CellName(cfg, idx%cfg.Width, idx/cfg.Width))
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,94 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules selects how a completed line and a full board are scored.
+type Rules int
+
+const (
+	// Standard: the player who completes a line of their mark wins.
+	Standard Rules = iota
+	// Misere: the player who completes a line of their mark loses.
+	Misere
+	// Wild: either player may place X or O, and the player who completes a
+	// line of either mark wins.
+	Wild
+	// Notakto: both players place X, and the player who completes a line loses.
+	Notakto
+	// NoDraws: as Standard, but a full board without a line is a win for O.
+	NoDraws
+)
+
+var rulesNames = []string{"standard", "misere", "wild", "notakto", "nodraws"}
+
+func (r Rules) String() string {
+	if r < 0 || int(r) >= len(rulesNames) {
+		return fmt.Sprintf("Rules(%d)", int(r))
+	}
+	return rulesNames[r]
+}
+
+// ParseRules returns the rules named s, as printed by Rules.String.
+func ParseRules(s string) (Rules, error) {
+	for i, name := range rulesNames {
+		if strings.EqualFold(s, name) {
+			return Rules(i), nil
+		}
+	}
+	return Standard, fmt.Errorf("unknown rules %q, use one of %s", s, strings.Join(rulesNames, ", "))
+}
+
+// marks returns the marks player may place.
+func (r Rules) marks(player int) []int {
+	switch r {
+	case Wild:
+		return []int{1, 2}
+	case Notakto:
+		return []int{1}
+	}
+	return []int{player}
+}
+
+// defaultMark returns the mark player places when the move does not say.
+func (r Rules) defaultMark(player int) int {
+	if r == Notakto {
+		return 1
+	}
+	return player
+}
+
+// allows reports whether player may place mark.
+func (r Rules) allows(player, mark int) bool {
+	if r == Wild {
+		return mark == 1 || mark == 2
+	}
+	return mark == r.defaultMark(player)
+}
+
+// outcome returns the status of a game in which mover has just played,
+// completing a line of mark lineMark (0 if no line was completed). full
+// reports whether the board has no empty cells left.
+func (r Rules) outcome(lineMark int, mover int, full bool) int {
+	if lineMark != 0 {
+		switch r {
+		case Misere:
+			return 3 - lineMark
+		case Wild:
+			return mover
+		case Notakto:
+			return 3 - mover
+		}
+		return lineMark
+	}
+
+	if full {
+		if r == NoDraws {
+			return 2
+		}
+		return 3 // Draw
+	}
+	return 0
+}
@@ -0,0 +1,213 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Environment creates boards of one configuration as env states.
+type Environment struct {
+	Config   Config
+	Bitboard bool // Whether to create Bitboards rather than Boards
+}
+
+func (e Environment) Name() string {
+	if e.Config == TicTacToe {
+		return "tictactoe"
+	}
+	if e.Config.Width == 3 && e.Config.Height == 3 && e.Config.K == 3 {
+		return "tictactoe " + e.Config.Rules.String()
+	}
+	return "mnk " + e.Config.String()
+}
+
+func (e Environment) NewState(start int) env.State {
+	if e.Bitboard {
+		return NewBitboardConfig(e.Config, start)
+	}
+	return NewBoardConfig(e.Config, start)
+}
+
+// Actions are cell indices. Under Wild rules, where the player also picks
+// the mark, actions placing O are offset by the number of cells.
+
+// ActionAt returns the action that places the default mark of the player
+// to move at (x, y).
+func (b *Board) ActionAt(x, y int) env.Action {
+	return b.ActionMark(x, y, b.DefaultMark(b.player))
+}
+
+// ActionMark returns the action that places mark at (x, y).
+func (b *Board) ActionMark(x, y, mark int) env.Action {
+	a := env.Action(b.Index(x, y))
+	if b.cfg.Rules == Wild && mark == 2 {
+		a += env.Action(len(b.board))
+	}
+	return a
+}
+
+// Coords returns the (x, y) cell marked by a.
+func (b *Board) Coords(a env.Action) (int, int) {
+	idx := int(a) % len(b.board)
+	return idx % b.cfg.Width, idx / b.cfg.Width
+}
+
+// Mark returns the mark a places.
+func (b *Board) Mark(a env.Action) int {
+	if b.cfg.Rules == Wild {
+		return 1 + int(a)/len(b.board)
+	}
+	return b.DefaultMark(b.player)
+}
+
+func (b *Board) Player() int {
+	return b.player
+}
+
+func (b *Board) Actions() []env.Action {
+	actions := []env.Action{}
+	if b.status != 0 {
+		return actions
+	}
+	for _, action := range b.GetPossibleMoves() {
+		actions = append(actions, b.ActionMark(action.X, action.Y, action.Mark))
+	}
+	return actions
+}
+
+func (b *Board) Apply(a env.Action) (env.State, bool) {
+	next := b.Clone()
+	if !next.Play(a) {
+		return nil, false
+	}
+	return next, true
+}
+
+// Play plays a in place, for env.Mutable.
+func (b *Board) Play(a env.Action) bool {
+	limit := len(b.board)
+	if b.cfg.Rules == Wild {
+		limit *= 2
+	}
+	if a < 0 || int(a) >= limit {
+		return false
+	}
+	x, y := b.Coords(a)
+	return b.MakeMoveMark(x, y, b.player, b.Mark(a))
+}
+
+// Copy returns Clone as an env.Mutable.
+func (b *Board) Copy() env.Mutable {
+	return b.Clone()
+}
+
+// History returns the moves made since the board was created as actions,
+// for env.Recorded.
+func (b *Board) History() []env.Action {
+	return history(b.Moves(), b.ActionMark)
+}
+
+// history converts moves to the actions that place their marks.
+func history(moves []Action, actionMark func(x, y, mark int) env.Action) []env.Action {
+	actions := make([]env.Action, len(moves))
+	for i, m := range moves {
+		actions[i] = actionMark(m.X, m.Y, m.Mark)
+	}
+	return actions
+}
+
+func (b *Board) Winner() int {
+	return b.status
+}
+
+func (b *Board) Reward(player int) float64 {
+	return env.TerminalReward(b.status, player)
+}
+
+func (b *Board) Key() string {
+	return strconv.FormatInt(b.ID(), 10)
+}
+
+// FormatAction formats a as "x y", or "x y X" and "x y O" under Wild rules.
+func (b *Board) FormatAction(a env.Action) string {
+	x, y := b.Coords(a)
+	if b.cfg.Rules == Wild {
+		return fmt.Sprintf("%d %d %s", x, y, []string{"", "X", "O"}[b.Mark(a)])
+	}
+	return fmt.Sprintf("%d %d", x, y)
+}
+
+// ParseAction parses a move written as FormatAction writes it, as a cell
+// name such as "b2" (see CellName) or, on 3x3 boards, as a digit laid out
+// like a numeric keypad, 7 being the top left cell and 3 the bottom right.
+// Under Wild rules every form is followed by the mark to place.
+func (b *Board) ParseAction(s string) (env.Action, error) {
+	parts := strings.Fields(s)
+	mark := b.DefaultMark(b.player)
+	if b.cfg.Rules == Wild {
+		if len(parts) < 2 {
+			return 0, errors.New("please enter the cell and the mark to place, e.g. \"1 1 O\" or \"b2 O\"")
+		}
+		switch strings.ToUpper(parts[len(parts)-1]) {
+		case "X":
+			mark = 1
+		case "O":
+			mark = 2
+		default:
+			return 0, errors.New("the mark must be X or O")
+		}
+		parts = parts[:len(parts)-1]
+	}
+
+	x, y, err := b.parseCell(parts)
+	if err != nil {
+		return 0, err
+	}
+	if b.board[b.Index(x, y)] != 0 {
+		return 0, errors.New("cell already taken")
+	}
+	return b.ActionMark(x, y, mark), nil
+}
+
+// parseCell parses a cell written as "x y", as a cell name or as a numpad
+// digit.
+func (b *Board) parseCell(parts []string) (int, int, error) {
+	switch len(parts) {
+	case 2:
+		x, err1 := strconv.Atoi(parts[0])
+		y, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || b.Index(x, y) < 0 {
+			return 0, 0, fmt.Errorf("invalid coordinates, enter x between 0 and %d and y between 0 and %d", b.cfg.Width-1, b.cfg.Height-1)
+		}
+		return x, y, nil
+	case 1:
+		cell := strings.ToLower(parts[0])
+		if b.cfg.Width == 3 && b.cfg.Height == 3 && len(cell) == 1 && cell[0] >= '1' && cell[0] <= '9' {
+			n := int(cell[0] - '1')
+			return n % 3, 2 - n/3, nil
+		}
+		if len(cell) >= 2 && cell[0] >= 'a' && cell[0] <= 'z' {
+			x := int(cell[0] - 'a')
+			row, err := strconv.Atoi(cell[1:])
+			if err == nil && b.Index(x, b.cfg.Height-row) >= 0 {
+				return x, b.cfg.Height - row, nil
+			}
+			return 0, 0, fmt.Errorf("invalid cell, enter a column from a to %c and a row from 1 to %d", 'a'+b.cfg.Width-1, b.cfg.Height)
+		}
+	}
+	return 0, 0, errors.New("please enter two numbers separated by a space, or a cell such as \"b2\"")
+}
+
+// CellName names (x, y) by its column letter, from a on the left, and its
+// row number, from 1 at the bottom, as in chess. It is empty for boards
+// wider than 26 columns.
+func CellName(cfg Config, x, y int) string {
+	if cfg.Width > 26 {
+		return ""
+	}
+	return fmt.Sprintf("%c%d", 'a'+x, cfg.Height-y)
+}
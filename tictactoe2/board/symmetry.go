@@ -0,0 +1,74 @@
+package board
+
+import "strconv"
+
+// symmetries returns the symmetries of a board of cfg as maps from each
+// cell to the cell it moves to: the rotations and reflections of a square
+// board, or the reflections and half turn of other boards. They map lines
+// to lines, so positions related by them have the same value.
+func symmetries(cfg Config) [][]int {
+	w, h := cfg.Width, cfg.Height
+	maps := []func(x, y int) (int, int){
+		func(x, y int) (int, int) { return x, y },
+		func(x, y int) (int, int) { return w - 1 - x, y },
+		func(x, y int) (int, int) { return x, h - 1 - y },
+		func(x, y int) (int, int) { return w - 1 - x, h - 1 - y },
+	}
+	if w == h {
+		maps = append(maps,
+			func(x, y int) (int, int) { return y, x },
+			func(x, y int) (int, int) { return h - 1 - y, x },
+			func(x, y int) (int, int) { return y, w - 1 - x },
+			func(x, y int) (int, int) { return h - 1 - y, w - 1 - x },
+		)
+	}
+
+	syms := make([][]int, len(maps))
+	for i, m := range maps {
+		syms[i] = make([]int, cfg.Cells())
+		for idx := range syms[i] {
+			x, y := m(idx%w, idx/w)
+			syms[i][idx] = x + w*y
+		}
+	}
+	return syms
+}
+
+// canonicalCells returns the smallest base-3 encoding of brd under the
+// symmetries of the board.
+func (g *geometry) canonicalCells(brd []int) int64 {
+	var least int64 = -1
+	for _, sym := range g.symmetries {
+		var cells int64
+		for idx, mark := range brd {
+			cells += int64(mark) * g.place[sym[idx]]
+		}
+		if least < 0 || cells < least {
+			least = cells
+		}
+	}
+	return least
+}
+
+// CanonicalID returns the smallest ID of the position's images under the
+// symmetries of the board, which is the same for every position equal to
+// it up to a rotation or reflection.
+func (b *Board) CanonicalID() int64 {
+	return (b.geo.canonicalCells(b.board)*2+int64(b.start-1))*2 + int64(b.player-1)
+}
+
+// CanonicalKey returns CanonicalID as a string, like Key.
+func (b *Board) CanonicalKey() string {
+	return strconv.FormatInt(b.CanonicalID(), 10)
+}
+
+// CanonicalID returns the same ID as Board.CanonicalID for the same
+// position.
+func (b *Bitboard) CanonicalID() int64 {
+	return (b.geo.canonicalCells(b.Get())*2+int64(b.start-1))*2 + int64(b.player-1)
+}
+
+// CanonicalKey returns CanonicalID as a string, like Key.
+func (b *Bitboard) CanonicalKey() string {
+	return strconv.FormatInt(b.CanonicalID(), 10)
+}
@@ -0,0 +1,226 @@
+// Package connect4 implements Connect Four: a 7x6 grid where marks drop to
+// the lowest empty cell of a column and four in a row wins.
+package connect4
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+const (
+	Width  = 7
+	Height = 6
+	// Each column uses Height+1 bits so that shifts never carry from one
+	// column into the next.
+	colBits = Height + 1
+)
+
+// bottom has the lowest bit of every column set.
+var bottom = func() uint64 {
+	var m uint64
+	for c := 0; c < Width; c++ {
+		m |= 1 << (c * colBits)
+	}
+	return m
+}()
+
+// columnOrder plays the centre columns first, which helps alpha-beta pruning.
+var columnOrder = []int{3, 2, 4, 1, 5, 0, 6}
+
+// State is a Connect Four position. Actions are column numbers, 0 to 6.
+// Bit c*7+r of a bitboard is row r (0 at the bottom) of column c.
+type State struct {
+	stones [3]uint64 // Stones of players 1 and 2
+	height [Width]int
+	start  int
+	player int
+	moves  int
+	status int
+}
+
+// Environment creates Connect Four games.
+type Environment struct{}
+
+func (Environment) Name() string {
+	return "connect4"
+}
+
+func (Environment) NewState(start int) env.State {
+	return New(start)
+}
+
+func New(start int) *State {
+	return &State{
+		start:  start,
+		player: start,
+	}
+}
+
+func (s *State) Player() int {
+	return s.player
+}
+
+func (s *State) Actions() []env.Action {
+	actions := []env.Action{}
+	if s.status != 0 {
+		return actions
+	}
+	for _, c := range columnOrder {
+		if s.height[c] < Height {
+			actions = append(actions, env.Action(c))
+		}
+	}
+	return actions
+}
+
+func (s *State) Apply(a env.Action) (env.State, bool) {
+	c := int(a)
+	if s.status != 0 || c < 0 || c >= Width || s.height[c] == Height {
+		return nil, false
+	}
+
+	next := *s
+	next.stones[s.player] |= 1 << (c*colBits + s.height[c])
+	next.height[c]++
+	next.moves++
+	if won(next.stones[s.player]) {
+		next.status = s.player
+	} else if next.moves == Width*Height {
+		next.status = 3
+	}
+	next.player = 3 - s.player
+	return &next, true
+}
+
+// won reports whether m has four in a row.
+func won(m uint64) bool {
+	for _, shift := range []int{1, colBits, colBits - 1, colBits + 1} { // vertical, horizontal, diagonals
+		t := m & (m >> shift)
+		if t&(t>>(2*shift)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *State) Winner() int {
+	return s.status
+}
+
+func (s *State) Reward(player int) float64 {
+	return env.TerminalReward(s.status, player)
+}
+
+// Key encodes the stones of the player to move plus the occupied cells,
+// which together identify the position in 49 bits, followed by a bit each
+// for the start player and the player to move.
+func (s *State) Key() string {
+	mask := s.stones[1] | s.stones[2]
+	key := s.stones[s.player] + mask + bottom
+	key = key*4 + uint64(s.start-1)*2 + uint64(s.player-1)
+	return strconv.FormatUint(key, 10)
+}
+
+func (s *State) FormatAction(a env.Action) string {
+	return strconv.Itoa(int(a))
+}
+
+// ParseAction parses a column number.
+func (s *State) ParseAction(text string) (env.Action, error) {
+	c, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || c < 0 || c >= Width {
+		return 0, fmt.Errorf("enter a column between 0 and %d", Width-1)
+	}
+	if s.height[c] == Height {
+		return 0, errors.New("column is full")
+	}
+	return env.Action(c), nil
+}
+
+// Cell returns the player whose stone is in column c, row r (0 at the
+// bottom), or 0 if it is empty.
+func (s *State) Cell(c, r int) int {
+	bit := uint64(1) << (c*colBits + r)
+	if s.stones[1]&bit != 0 {
+		return 1
+	} else if s.stones[2]&bit != 0 {
+		return 2
+	}
+	return 0
+}
+
+func (s *State) Print() {
+	fmt.Println("Current Board:")
+	for r := Height - 1; r >= 0; r-- {
+		for c := 0; c < Width; c++ {
+			switch s.Cell(c, r) {
+			case 0:
+				fmt.Print(" .")
+			case 1:
+				fmt.Print(" X")
+			case 2:
+				fmt.Print(" O")
+			}
+		}
+		fmt.Println()
+	}
+	for c := 0; c < Width; c++ {
+		fmt.Printf(" %d", c)
+	}
+	fmt.Println()
+	fmt.Println("Next Player:", s.player)
+}
+
+// windows lists every group of four cells in a line as bitmasks.
+var windows = func() []uint64 {
+	var ws []uint64
+	directions := [4][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+	for c := 0; c < Width; c++ {
+		for r := 0; r < Height; r++ {
+			for _, d := range directions {
+				endC, endR := c+3*d[0], r+3*d[1]
+				if endC < 0 || endC >= Width || endR < 0 || endR >= Height {
+					continue
+				}
+				var w uint64
+				for i := 0; i < 4; i++ {
+					w |= 1 << ((c+i*d[0])*colBits + r + i*d[1])
+				}
+				ws = append(ws, w)
+			}
+		}
+	}
+	return ws
+}()
+
+// centre has the bits of the middle column.
+var centre = uint64((1<<Height)-1) << (3 * colBits)
+
+// Evaluate scores the position for player without searching, between -1
+// and 1. Every window of four that only one player occupies counts for that
+// player, more so the fuller it is, and stones in the centre column count
+// extra.
+func (s *State) Evaluate(player int) float64 {
+	weights := [4]float64{0, 1, 4, 16}
+	mine, theirs := s.stones[player], s.stones[3-player]
+
+	score := 0.0
+	for _, w := range windows {
+		m := bits.OnesCount64(mine & w)
+		t := bits.OnesCount64(theirs & w)
+		if t == 0 && m > 0 && m < 4 {
+			score += weights[m]
+		} else if m == 0 && t > 0 && t < 4 {
+			score -= weights[t]
+		}
+	}
+	score += 3 * float64(bits.OnesCount64(mine&centre)-bits.OnesCount64(theirs&centre))
+
+	return math.Tanh(score / 100)
+}
@@ -0,0 +1,77 @@
+package connect4
+
+import (
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// play drops stones in columns, X first.
+func play(t *testing.T, columns ...int) *State {
+	s := New(1)
+	for _, c := range columns {
+		next, ok := s.Apply(env.Action(c))
+		if !ok {
+			t.Fatalf("column %d rejected", c)
+		}
+		s = next.(*State)
+	}
+	return s
+}
+
+// Test four in a row in every direction
+func TestWinner(t *testing.T) {
+	tests := []struct {
+		columns []int
+		want    int
+	}{
+		{[]int{0, 1, 0, 1, 0, 1, 0}, 1},                      // Vertical
+		{[]int{0, 0, 1, 1, 2, 2, 3}, 1},                      // Horizontal
+		{[]int{0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3}, 1},          // Diagonal up
+		{[]int{6, 5, 5, 4, 4, 3, 4, 3, 3, 0, 3}, 1},          // Diagonal down
+		{[]int{6, 0, 1, 0, 1, 0, 1, 0}, 2},                   // O wins
+		{[]int{0, 1, 0, 1, 0, 1}, 0},                         // Three is not enough
+		{[]int{0, 0, 0, 1, 1, 1, 6, 6, 6, 5, 5, 5, 3, 3}, 0}, // No wrap between columns
+	}
+	for _, tt := range tests {
+		if got := play(t, tt.columns...).Winner(); got != tt.want {
+			t.Errorf("%v: Winner() = %d; want %d", tt.columns, got, tt.want)
+		}
+	}
+}
+
+// Test that a full column rejects moves
+func TestFullColumn(t *testing.T) {
+	s := play(t, 0, 0, 0, 0, 0, 0)
+	if _, ok := s.Apply(0); ok {
+		t.Errorf("Apply on a full column succeeded")
+	}
+}
+
+// Test that transpositions share a key and different positions do not
+func TestKey(t *testing.T) {
+	a := play(t, 0, 1, 2)
+	b := play(t, 2, 1, 0)
+	c := play(t, 0, 2, 1)
+	if a.Key() != b.Key() {
+		t.Errorf("transposed positions have different keys")
+	}
+	if a.Key() == c.Key() {
+		t.Errorf("different positions share key %s", a.Key())
+	}
+}
+
+// Test that the depth-limited search blocks a threat and takes a win
+func TestAlphaBeta(t *testing.T) {
+	// X threatens column 3 with three in a row along the bottom
+	s := play(t, 0, 0, 1, 1, 2)
+	if got := player.NewAlphaBetaPlayer(2, 4).MakeMove(s); got != 3 {
+		t.Errorf("O plays %d; want 3 to block", got)
+	}
+	// X to move wins in column 3
+	s = play(t, 0, 0, 1, 1, 2, 2)
+	if got := player.NewAlphaBetaPlayer(1, 4).MakeMove(s); got != 3 {
+		t.Errorf("X plays %d; want 3 to win", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Package curriculum chooses the positions training games start from, so
+// that the learner meets positions that games from the empty board rarely
+// reach, such as endgames and the positions it gets wrong.
+package curriculum
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/statespace"
+)
+
+// Curriculum chooses the starting position of each training game.
+type Curriculum interface {
+	// Start returns the position the next game starts from, for a learner
+	// playing seat, when a fraction progress of training is done.
+	Start(seat int, progress float64) env.State
+}
+
+// Kind names a curriculum.
+type Kind int
+
+const (
+	// None starts every game from the starting position.
+	None Kind = iota
+	// Endgame starts games near the end first, see NewEndgame.
+	Endgame
+	// Uniform starts games anywhere, see NewUniform.
+	Uniform
+	// Blunders starts games where the learner goes wrong, see NewBlunders.
+	Blunders
+)
+
+var kindNames = []string{"none", "endgame", "uniform", "blunders"}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// ParseKind parses the name of a curriculum.
+func ParseKind(s string) (Kind, error) {
+	for i, name := range kindNames {
+		if s == name {
+			return Kind(i), nil
+		}
+	}
+	return None, errors.New("unknown curriculum " + s + ", use none, endgame, uniform or blunders")
+}
+
+// mixed starts some games from the starting position.
+type mixed struct {
+	c    Curriculum
+	root env.State
+	mix  float64
+}
+
+// Mix returns a curriculum that starts a fraction mix of games from the
+// positions c chooses and the rest from root, so the learner still plays
+// whole games.
+func Mix(c Curriculum, root env.State, mix float64) Curriculum {
+	return &mixed{c: c, root: root, mix: mix}
+}
+
+func (m *mixed) Start(seat int, progress float64) env.State {
+	if rand.Float64() < m.mix {
+		return m.c.Start(seat, progress)
+	}
+	return m.root
+}
+
+// endgame starts games deep in the game early in training.
+type endgame struct {
+	root  env.State
+	depth int
+}
+
+// NewEndgame returns a curriculum that starts games (1 - progress) * depth
+// random moves after root, so training works back from the end of the game
+// to its start. Moves that would end the game are not played, so positions
+// are reached before it is over.
+func NewEndgame(root env.State, depth int) Curriculum {
+	return &endgame{root: root, depth: depth}
+}
+
+func (e *endgame) Start(seat int, progress float64) env.State {
+	moves := int((1 - progress) * float64(e.depth))
+	s := e.root
+	for i := 0; i < moves; i++ {
+		var next env.State
+		actions := s.Actions()
+		for _, j := range rand.Perm(len(actions)) {
+			if n, _ := s.Apply(actions[j]); n.Winner() == 0 {
+				next = n
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// uniform starts games from every position alike.
+type uniform struct {
+	positions [3][]env.State // By the player to move
+}
+
+// NewUniform returns a curriculum that starts games from an unfinished
+// position reachable from root with the learner to move, drawn uniformly.
+// It enumerates every position, so it is only feasible for small games.
+func NewUniform(root env.State) Curriculum {
+	u := &uniform{}
+	space := statespace.Enumerate(root)
+	for seat := 1; seat <= 2; seat++ {
+		for _, pos := range space.ToMove(seat).Positions {
+			u.positions[seat] = append(u.positions[seat], pos.State)
+		}
+	}
+	return u
+}
+
+func (u *uniform) Start(seat int, progress float64) env.State {
+	positions := u.positions[seat]
+	return positions[rand.Intn(len(positions))]
+}
+
+// blunders starts games from the learner's blunders.
+type blunders struct {
+	root      env.State
+	learner   *player.LearnerPlayer
+	every     int
+	games     int
+	positions [3][]env.State // By the player to move
+}
+
+// NewBlunders returns a curriculum that starts games from positions where
+// a greedy move of learner changes the game-theoretic value, found by
+// auditing it against minimax every every games, or from root when it
+// makes none. Auditing searches every position, so it is only feasible for
+// small games.
+func NewBlunders(root env.State, learner *player.LearnerPlayer, every int) Curriculum {
+	return &blunders{root: root, learner: learner, every: every}
+}
+
+func (b *blunders) Start(seat int, progress float64) env.State {
+	if b.games%b.every == 0 {
+		for side := 1; side <= 2; side++ {
+			found, _ := eval.Audit(b.learner, b.root, side)
+			b.positions[side] = b.positions[side][:0]
+			for _, bl := range found {
+				b.positions[side] = append(b.positions[side], bl.State)
+			}
+		}
+	}
+	b.games++
+
+	positions := b.positions[seat]
+	if len(positions) == 0 {
+		return b.root
+	}
+	return positions[rand.Intn(len(positions))]
+}
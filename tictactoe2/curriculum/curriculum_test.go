@@ -0,0 +1,42 @@
+package curriculum
+
+import (
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+func TestUniform(t *testing.T) {
+	u := NewUniform(board.NewBoard(1)).(*uniform)
+	if n := len(u.positions[1]) + len(u.positions[2]); n != 4520 {
+		t.Errorf("%d unfinished positions, want 4520", n)
+	}
+	for seat := 1; seat <= 2; seat++ {
+		for i := 0; i < 100; i++ {
+			s := u.Start(seat, 0)
+			if s.Player() != seat || s.Winner() != 0 {
+				t.Fatalf("Start(%d) = %v, want an unfinished position with %d to move", seat, s, seat)
+			}
+		}
+	}
+}
+
+func TestEndgame(t *testing.T) {
+	root := board.NewBoard(1)
+	e := NewEndgame(root, 8)
+	// it stops early where every move ends the game
+	if s := e.Start(1, 0); len(s.Actions()) > 4 || s.Winner() != 0 {
+		t.Errorf("Start at progress 0 = %v, want an unfinished position near the end", s)
+	}
+	if s := e.Start(1, 1); s.Key() != root.Key() {
+		t.Errorf("Start at progress 1 = %v, want the starting position", s)
+	}
+}
+
+func TestMix(t *testing.T) {
+	var root env.State = board.NewBoard(1)
+	if s := Mix(NewEndgame(root, 8), root, 0).Start(1, 0); s != root {
+		t.Errorf("Start with mix 0 = %v, want the starting position", s)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/remote"
+)
+
+// engineFactory creates the games and players of an engine. Each newgame
+// starts from the flags' choices and applies the controller's spec.
+func (m *models) engineFactory(defaults gameSpec) remote.Factory {
+	return func(pairs []string, seat int) (env.Environment, player.Player, error) {
+		spec, err := defaults.parseProtocol(pairs)
+		if err != nil {
+			return nil, nil, err
+		}
+		e, err := spec.environment()
+		if err != nil {
+			return nil, nil, err
+		}
+		ai, err := m.newAI(spec, seat)
+		if err != nil {
+			return nil, nil, err
+		}
+		return e, ai, nil
+	}
+}
+
+// engine exposes an AI player over the remote protocol, on stdin and
+// stdout or to every client of a TCP address.
+func engine(args []string) {
+	fs := flag.NewFlagSet("engine", flag.ExitOnError)
+	opts := gameFlags(fs)
+	dir := fs.String("models", ".", "directory of the model files controllers may choose")
+	listen := fs.String("listen", "", "TCP address to listen on instead of using stdin and stdout")
+	fs.Parse(args)
+
+	defaults := opts.spec()
+	if _, err := defaults.parseProtocol(nil); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid engine:", err)
+		os.Exit(2)
+	}
+	m := &models{dir: *dir, loaded: map[string]*player.LearnerPlayer{}}
+	factory := m.engineFactory(defaults)
+
+	if *listen == "" {
+		if err := remote.Serve(os.Stdin, os.Stdout, factory); err != nil {
+			fmt.Fprintln(os.Stderr, "Error serving:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Println("Error listening:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Engine listening on", l.Addr())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			fmt.Println("Error accepting:", err)
+			os.Exit(1)
+		}
+		go func() {
+			defer conn.Close()
+			if err := remote.Serve(conn, conn, factory); err != nil {
+				fmt.Println("Error serving", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
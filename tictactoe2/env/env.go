@@ -0,0 +1,98 @@
+// Package env defines the interface between two-player, turn-based games and
+// the players and learners that play them.
+package env
+
+// Action identifies a move. Its meaning is defined by the game, for example
+// the cell index on a tic-tac-toe board.
+type Action int
+
+// State is an immutable position in a game. Players are numbered 1 and 2.
+type State interface {
+	// Player returns the player to move.
+	Player() int
+	// Actions returns the legal actions of the player to move. It is empty
+	// once the game is over.
+	Actions() []Action
+	// Apply returns the state after the player to move plays a.
+	// Returns false if a is not legal.
+	Apply(a Action) (State, bool)
+	// Winner returns:
+	//
+	//	0 if the game continues,
+	//	1 if player 1 wins,
+	//	2 if player 2 wins,
+	//	3 if the game is a draw
+	Winner() int
+	// Reward returns the reward for player in this state: 1 for a win,
+	// -1 for a loss and 0 otherwise.
+	Reward(player int) float64
+	// Key returns a compact string that identifies the state, including
+	// the player to move. It is used to index tabular models.
+	Key() string
+	// FormatAction and ParseAction convert actions to and from the text
+	// a human player types.
+	FormatAction(a Action) string
+	ParseAction(s string) (Action, error)
+	// Print prints the state in a human-readable format.
+	Print()
+}
+
+// Environment creates the starting states of a game.
+type Environment interface {
+	Name() string
+	// NewState returns the starting position with start to move.
+	NewState(start int) State
+}
+
+// TerminalReward implements State.Reward for games that only reward the
+// final result.
+func TerminalReward(winner int, player int) float64 {
+	switch winner {
+	case player:
+		return 1
+	case 3 - player:
+		return -1
+	}
+	return 0
+}
+
+// Heuristic is implemented by states whose value can be estimated without
+// searching to the end of the game, for depth-limited search.
+type Heuristic interface {
+	// Evaluate returns an estimate of the state's value for player,
+	// between -1 (certain loss) and 1 (certain win).
+	Evaluate(player int) float64
+}
+
+// Mutable is implemented by states that can play and take back actions in
+// place. Searches use it to walk the game tree on one copy of a state
+// instead of creating a state for every node.
+type Mutable interface {
+	State
+	// Copy returns an independent copy of the state.
+	Copy() Mutable
+	// Play plays a in place. It returns false, changing nothing, if a is
+	// illegal.
+	Play(a Action) bool
+	// UndoMove takes back the last action played.
+	UndoMove() bool
+}
+
+// Configured is implemented by states whose game depends on a
+// configuration, such as the size and rules of a board. States of
+// different configurations may share keys, so anything that caches values
+// by Key must not mix states whose ConfigKey differs.
+type Configured interface {
+	State
+	// ConfigKey identifies the configuration.
+	ConfigKey() string
+}
+
+// Recorded is implemented by states that remember how they were reached,
+// such as board.Board with its move stack: History played in turn from
+// the environment's NewState(GetStart()) reaches the state.
+type Recorded interface {
+	State
+	GetStart() int
+	History() []Action
+}
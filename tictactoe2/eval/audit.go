@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// Blunder is a position where one of the learner's greedy moves changes the
+// game-theoretic value of the game.
+type Blunder struct {
+	State env.State
+	Depth int        // Number of moves played before State
+	Move  env.Action // The greedy move that loses value
+	Value int        // Value of the position for the side to move (1 win, 0 draw, -1 loss)
+	After int        // Value after Move is played
+	Best  []env.Action
+}
+
+// Audit compares the learner's greedy moves with the minimax-optimal moves
+// in every position reachable from root where side is to move. It returns
+// the blunders found and the number of positions checked.
+func Audit(lp *player.LearnerPlayer, root env.State, side int) ([]Blunder, int) {
+	frozen := lp.Frozen()
+	frozen.SetPlayer(side)
+	oracle := player.NewOraclePlayer(side)
+
+	blunders := []Blunder{}
+	positions := Positions(root, side)
+	for _, pos := range positions {
+		best, value := oracle.OptimalMoves(pos.State)
+		for _, move := range frozen.GreedyMoves(pos.State) {
+			after := oracle.MoveValue(pos.State, move)
+			if after == value {
+				continue
+			}
+			blunders = append(blunders, Blunder{
+				State: pos.State,
+				Depth: pos.Depth,
+				Move:  move,
+				Value: value,
+				After: after,
+				Best:  best,
+			})
+		}
+	}
+
+	return blunders, len(positions)
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/statespace"
+)
+
+// Result counts the outcomes of a match from one player's point of view.
+type Result struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+func (r Result) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+func (r Result) WinRate() float64 {
+	return rate(r.Wins, r.Games())
+}
+
+func (r Result) DrawRate() float64 {
+	return rate(r.Draws, r.Games())
+}
+
+func (r Result) LossRate() float64 {
+	return rate(r.Losses, r.Games())
+}
+
+func (r Result) Add(o Result) Result {
+	return Result{
+		Wins:   r.Wins + o.Wins,
+		Draws:  r.Draws + o.Draws,
+		Losses: r.Losses + o.Losses,
+	}
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// Match plays games games of e between p, seated as seat (1 - X, 2 - O),
+// and opponent. X always starts.
+func Match(e env.Environment, p, opponent player.Player, seat int, games int) Result {
+	p.SetPlayer(seat)
+	opponent.SetPlayer(3 - seat)
+
+	players := [3]player.Player{}
+	players[seat] = p
+	players[3-seat] = opponent
+
+	r := Result{}
+	for i := 0; i < games; i++ {
+		g := game.NewGameState(e.NewState(1), players[1], players[2], true)
+		switch g.Play() {
+		case seat:
+			r.Wins++
+		case 3:
+			r.Draws++
+		default:
+			r.Losses++
+		}
+	}
+	return r
+}
+
+// Report is the outcome of evaluating a learner against fixed opponents.
+type Report struct {
+	Games     int     // Games played per opponent and seat
+	MinimaxX  Result  // Learner as X against perfect play
+	MinimaxO  Result  // Learner as O against perfect play
+	RandomX   Result  // Learner as X against RandomPlayer
+	RandomO   Result  // Learner as O against RandomPlayer
+	OptimalX  float64 // Fraction of positions with X to move where the learner plays optimally
+	OptimalO  float64 // Same as OptimalX for O
+	Positions int     // Number of positions checked for OptimalX and OptimalO
+}
+
+// Evaluator evaluates a learner against perfect and random play. Perfect
+// play comes from OraclePlayer, which looks standard tic-tac-toe up and
+// keeps the search caches of other games between evaluations.
+type Evaluator struct {
+	env     env.Environment
+	games   int
+	perfect [3]*player.OraclePlayer
+	random  *player.RandomPlayer
+}
+
+// NewEvaluator creates an evaluator for environment e that plays games
+// games per opponent and seat. OraclePlayer must be able to solve e.
+func NewEvaluator(e env.Environment, games int) *Evaluator {
+	ev := &Evaluator{
+		env:    e,
+		games:  games,
+		random: player.NewRandomPlayer(2),
+	}
+	for p := 1; p <= 2; p++ {
+		ev.perfect[p] = player.NewOraclePlayer(p)
+	}
+	return ev
+}
+
+// Evaluate freezes lp and measures it. The learner's model is not modified.
+func (e *Evaluator) Evaluate(lp *player.LearnerPlayer) Report {
+	frozen := lp.Frozen()
+
+	r := Report{Games: e.games}
+	r.MinimaxX = Match(e.env, frozen, e.perfect[2], 1, e.games)
+	r.MinimaxO = Match(e.env, frozen, e.perfect[1], 2, e.games)
+	r.RandomX = Match(e.env, frozen, e.random, 1, e.games)
+	r.RandomO = Match(e.env, frozen, e.random, 2, e.games)
+
+	var checked, optimal [3]int
+	for side := 1; side <= 2; side++ {
+		frozen.SetPlayer(side)
+		for _, pos := range Positions(e.env.NewState(1), side) {
+			checked[side]++
+			best, _ := e.perfect[side].OptimalMoves(pos.State)
+			if isSubset(frozen.GreedyMoves(pos.State), best) {
+				optimal[side]++
+			}
+		}
+	}
+	r.OptimalX = rate(optimal[1], checked[1])
+	r.OptimalO = rate(optimal[2], checked[2])
+	r.Positions = checked[1] + checked[2]
+
+	return r
+}
+
+func (r Report) String() string {
+	vsMinimax := r.MinimaxX.Add(r.MinimaxO)
+	vsRandom := r.RandomX.Add(r.RandomO)
+	return fmt.Sprintf(
+		"vs minimax: draw %.1f%% (X %.1f%%, O %.1f%%) | vs random: win %.1f%% (X %.1f%%, O %.1f%%) | optimal moves: %.1f%% (X %.1f%%, O %.1f%%)",
+		100*vsMinimax.DrawRate(), 100*r.MinimaxX.DrawRate(), 100*r.MinimaxO.DrawRate(),
+		100*vsRandom.WinRate(), 100*r.RandomX.WinRate(), 100*r.RandomO.WinRate(),
+		100*(r.OptimalX+r.OptimalO)/2, 100*r.OptimalX, 100*r.OptimalO,
+	)
+}
+
+// Position is a state reached from a starting state.
+type Position = statespace.Position
+
+// Positions returns every unfinished position reachable from root in which
+// side is to move.
+func Positions(root env.State, side int) []Position {
+	return statespace.Enumerate(root).ToMove(side).Positions
+}
+
+// isSubset reports whether every action in moves is also in allowed.
+func isSubset(moves, allowed []env.Action) bool {
+	for _, m := range moves {
+		found := false
+		for _, a := range allowed {
+			if m == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// seededPlayer picks uniformly among the legal moves with its own source,
+// so that its games repeat, and records the players it moved for.
+type seededPlayer struct {
+	player.RandomPlayer
+	rng   *rand.Rand
+	moved map[int]bool
+}
+
+func newSeededPlayer(seed int64) *seededPlayer {
+	return &seededPlayer{rng: rand.New(rand.NewSource(seed)), moved: map[int]bool{}}
+}
+
+func (p *seededPlayer) MakeMove(state env.State) env.Action {
+	p.moved[state.Player()] = true
+	actions := state.Actions()
+	return actions[p.rng.Intn(len(actions))]
+}
+
+// Test that random play never beats the oracle and every game is counted
+func TestMatchRandomAgainstOracle(t *testing.T) {
+	e := board.Environment{Config: board.TicTacToe}
+	const games = 200
+	for seat := 1; seat <= 2; seat++ {
+		p := newSeededPlayer(1)
+		r := Match(e, p, player.NewOraclePlayer(1), seat, games)
+		if r.Games() != games {
+			t.Errorf("seat %d: %d wins + %d draws + %d losses = %d games; want %d",
+				seat, r.Wins, r.Draws, r.Losses, r.Games(), games)
+		}
+		if r.Wins != 0 {
+			t.Errorf("seat %d: random play won %d games against the oracle", seat, r.Wins)
+		}
+		if p.GetPlayer() != seat || len(p.moved) != 1 || !p.moved[seat] {
+			t.Errorf("seat %d: the evaluated player played %v as player %d", seat, p.moved, p.GetPlayer())
+		}
+	}
+}
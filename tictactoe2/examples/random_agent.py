@@ -0,0 +1,31 @@
+#!/usr/bin/env python3
+"""A remote engine for tt that plays a random legal move.
+
+    tt match -engine "python3 examples/random_agent.py" -ai minimax
+
+It ignores the game and chooses among the legal actions sent with genmove;
+see the remote package for the protocol.
+"""
+import random
+import sys
+
+
+def main():
+    for line in sys.stdin:
+        fields = line.split()
+        if not fields:
+            continue
+        command = fields[0]
+        if command == "tt":
+            answer = "= tt 1"
+        elif command == "genmove":
+            answer = "= " + random.choice(fields[2:])
+        else:
+            answer = "="
+        print(answer, flush=True)
+        if command == "quit":
+            break
+
+
+if __name__ == "__main__":
+    main()
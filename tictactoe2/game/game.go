@@ -1,20 +1,45 @@
 package game
 
 import (
+	"fmt"
+
 	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
 	"github.com/param108/reinforcement-learning/tictactoe2/player"
 )
 
 type Game struct {
-	brd *board.Board
+	state env.State
 	// 1 - x 2 -o
-	players [3]player.Player
-	silent  bool // If true, no output is printed
+	players  [3]player.Player
+	silent   bool        // If true, no output is printed
+	history  []env.State // States before each move, for Undo
+	resigned int         // Player who resigned, 0 if none
 }
 
 func NewGame(player int, xplayer, oplayer player.Player, silent bool) *Game {
+	return NewGameConfig(board.TicTacToe, player, xplayer, oplayer, silent)
+}
+
+// NewGameConfig creates a game on a board of configuration cfg.
+func NewGameConfig(cfg board.Config, player int, xplayer, oplayer player.Player, silent bool) *Game {
+	return NewGameState(board.NewBoardConfig(cfg, player), xplayer, oplayer, silent)
+}
+
+// NewGamePosition creates a game on a board of cfg starting from pos,
+// written as board.Parse reads it, e.g. "X.O/.X./..O x".
+func NewGamePosition(cfg board.Config, pos string, xplayer, oplayer player.Player, silent bool) (*Game, error) {
+	b, err := board.Parse(cfg, pos)
+	if err != nil {
+		return nil, err
+	}
+	return NewGameState(b, xplayer, oplayer, silent), nil
+}
+
+// NewGameState creates a game of any environment starting from state.
+func NewGameState(state env.State, xplayer, oplayer player.Player, silent bool) *Game {
 	g := &Game{
-		brd:    board.NewBoard(player),
+		state:  state,
 		silent: silent,
 	}
 
@@ -24,27 +49,100 @@ func NewGame(player int, xplayer, oplayer player.Player, silent bool) *Game {
 	return g
 }
 
+// Play plays the game to the end and returns the winner (3 for a draw). A
+// player may also return player.Undo, player.Resign or player.Quit instead
+// of a move; Play returns 0 if the game was abandoned.
 func (g *Game) Play() int {
-	for g.brd.CheckWin() == 0 {
+	for g.Winner() == 0 {
 		if !g.silent {
 			// Print the board
-			g.brd.Print()
+			g.state.Print()
+		}
+		mover := g.state.Player()
+		action := g.players[mover].MakeMove(g.state)
+		switch action {
+		case player.Undo:
+			if !g.Undo(mover) && !g.silent {
+				fmt.Println("There is no move to undo.")
+			}
+		case player.Resign:
+			g.Resign()
+		case player.Quit:
+			return 0
+		default:
+			g.Apply(action)
+		}
+	}
+
+	return g.Winner()
+}
+
+// Winner returns the winner of the game, 3 for a draw or 0 while it is
+// being played.
+func (g *Game) Winner() int {
+	if g.resigned != 0 {
+		return 3 - g.resigned
+	}
+	return g.state.Winner()
+}
+
+// Resign ends the game as a loss for the player to move.
+func (g *Game) Resign() {
+	if g.Winner() != 0 {
+		return
+	}
+	g.resigned = g.state.Player()
+	g.players[3-g.resigned].Win()
+	g.players[g.resigned].Lose()
+}
+
+// Undo takes back the last move of player p and every move after it. It
+// returns false, changing nothing, if p has not moved or the game is over.
+// Players that learn from their moves are not told.
+func (g *Game) Undo(p int) bool {
+	if g.Winner() != 0 {
+		return false
+	}
+	for i := len(g.history) - 1; i >= 0; i-- {
+		if g.history[i].Player() == p {
+			g.state = g.history[i]
+			g.history = g.history[:i]
+			return true
 		}
-		x, y, player := g.players[g.brd.NextPlayer()].MakeMove(g.brd)
-		g.brd.MakeMove(x, y, player)
 	}
+	return false
+}
+
+// Apply plays action for the player to move and, if that ends the game,
+// tells the players who won. It returns false, changing nothing, if the
+// game is over or the action is illegal.
+func (g *Game) Apply(action env.Action) bool {
+	if g.Winner() != 0 {
+		return false
+	}
+	next, ok := g.state.Apply(action)
+	if !ok {
+		return false
+	}
+	g.history = append(g.history, g.state)
+	g.state = next
 
-	if g.brd.CheckWin() == 1 {
+	if g.state.Winner() == 1 {
 		g.players[1].Win()
 		g.players[2].Lose()
-	} else if g.brd.CheckWin() == 2 {
+	} else if g.state.Winner() == 2 {
 		g.players[2].Win()
 		g.players[1].Lose()
 	}
+	return true
+}
 
-	return g.brd.CheckWin()
+// Player returns the player whose turn it is.
+func (g *Game) Player() player.Player {
+	return g.players[g.state.Player()]
 }
 
-func (g *Game) GetBoard() *board.Board {
-	return g.brd
+// GetState returns the current state of the game.
+func (g *Game) GetState() env.State {
+	return g.state
 }
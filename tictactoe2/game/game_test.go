@@ -0,0 +1,54 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// scripted plays its actions in order.
+type scripted struct {
+	player.RandomPlayer
+	actions []env.Action
+	won     bool
+}
+
+func (s *scripted) MakeMove(state env.State) env.Action {
+	a := s.actions[0]
+	s.actions = s.actions[1:]
+	return a
+}
+
+func (s *scripted) Win() {
+	s.won = true
+}
+
+// Test undoing moves, resigning and quitting
+func TestGameRequests(t *testing.T) {
+	b := board.NewBoard(1)
+	x := &scripted{actions: []env.Action{
+		b.ActionAt(0, 0),
+		player.Undo, // back to the empty board
+		b.ActionAt(1, 1),
+		player.Resign,
+	}}
+	o := &scripted{actions: []env.Action{
+		player.Undo, // nothing to undo
+		b.ActionAt(2, 2),
+		b.ActionAt(0, 0),
+	}}
+	g := NewGameState(b, x, o, true)
+	if result := g.Play(); result != 2 || !o.won {
+		t.Errorf("Play() = %d after X resigned; want 2", result)
+	}
+	if cells := g.GetState().(*board.Board).Get(); cells[0] != 2 || cells[4] != 1 || cells[8] != 0 {
+		t.Errorf("board after undo = %v", cells)
+	}
+
+	g = NewGameState(board.NewBoard(1), &scripted{actions: []env.Action{player.Quit}}, o, true)
+	if result := g.Play(); result != 0 {
+		t.Errorf("Play() = %d after quitting; want 0", result)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// inspect prints a position given in the notation of board.Parse, with the
+// learner's value and, on boards small enough to solve, the game-theoretic
+// value of each legal move.
+func inspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	pos := fs.String("pos", "", "position to inspect, e.g. \"X.O/.X./..O x\"")
+	model := fs.String("model", "learner_player.json", "model file for the learner's values")
+	boardConfig := boardFlags(fs)
+	fs.Parse(args)
+
+	cfg := boardConfig()
+	b, err := board.Parse(cfg, *pos)
+	if err != nil {
+		fmt.Println("Invalid position:", err)
+		os.Exit(2)
+	}
+
+	b.Print()
+	fmt.Println("Position:", b.String(), "started by", []string{"", "X", "O"}[b.GetStart()])
+	fmt.Println("Key:", b.Key())
+	switch b.Winner() {
+	case 0:
+	case 3:
+		fmt.Println("The game is drawn.")
+		return
+	default:
+		fmt.Printf("Player %d has won.\n", b.Winner())
+		return
+	}
+
+	lp := player.NewLearnerPlayer(b.Player(), 0, 0, "frozen")
+	if err := lp.LoadModel(*model); err != nil {
+		fmt.Println("No learner values:", err)
+		lp = nil
+	}
+	var mp *player.OraclePlayer
+	if cfg.Cells() <= board.TicTacToe.Cells() {
+		mp = player.NewOraclePlayer(b.Player())
+		_, value := mp.OptimalMoves(b)
+		fmt.Printf("Value for player %d with perfect play: %s\n", b.Player(), valueNames[value])
+	}
+
+	fmt.Println()
+	fmt.Printf("%-6s %-6s %-8s %s\n", "Move", "Cell", "Learner", "Minimax")
+	var values []float64
+	if lp != nil {
+		values = lp.ActionValues(b)
+	}
+	for i, a := range b.Actions() {
+		x, y := b.Coords(a)
+		learner, minimax := "-", "-"
+		if lp != nil {
+			learner = fmt.Sprintf("%.3f", values[i])
+		}
+		if mp != nil {
+			minimax = valueNames[mp.MoveValue(b, a)]
+		}
+		fmt.Printf("%-6s %-6s %-8s %s\n", b.FormatAction(a), board.CellName(cfg, x, y), learner, minimax)
+	}
+}
@@ -0,0 +1,100 @@
+// Package league keeps a pool of frozen snapshots of a learner to train it
+// against, so that in self-play it meets increasingly strong versions of
+// itself rather than untrained opponents.
+package league
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// Sampling selects how opponents are drawn from the pool.
+type Sampling int
+
+const (
+	// Uniform draws every snapshot with equal probability.
+	Uniform Sampling = iota
+	// Prioritized draws snapshots in proportion to how badly the learner
+	// does against them, 1 - its score, where a score counts wins as 1 and
+	// draws as 1/2. Scores start at 1/2 and are smoothed by one imaginary
+	// draw, so new snapshots are still tried.
+	Prioritized
+)
+
+// ParseSampling parses "uniform" or "prioritized".
+func ParseSampling(s string) (Sampling, error) {
+	switch s {
+	case "uniform":
+		return Uniform, nil
+	case "prioritized":
+		return Prioritized, nil
+	}
+	return 0, errors.New("unknown sampling " + s + ", use uniform or prioritized")
+}
+
+// Snapshot is a frozen copy of the learner and the learner's results
+// against it.
+type Snapshot struct {
+	Player  *player.LearnerPlayer
+	Games   int         // Games the learner had played when the snapshot was taken
+	Results eval.Result // The learner's results against the snapshot
+}
+
+// Score returns the learner's smoothed score against the snapshot.
+func (s *Snapshot) Score() float64 {
+	return (float64(s.Results.Wins) + float64(s.Results.Draws)/2 + 0.5) / float64(s.Results.Games()+1)
+}
+
+// Pool holds up to size snapshots, dropping the oldest when full.
+type Pool struct {
+	snapshots []*Snapshot
+	size      int
+	sampling  Sampling
+}
+
+func NewPool(size int, sampling Sampling) *Pool {
+	return &Pool{
+		size:     size,
+		sampling: sampling,
+	}
+}
+
+// Add takes a snapshot of learner, which has played games games.
+func (p *Pool) Add(learner *player.LearnerPlayer, games int) {
+	p.snapshots = append(p.snapshots, &Snapshot{Player: learner.Snapshot(), Games: games})
+	if len(p.snapshots) > p.size {
+		p.snapshots = p.snapshots[1:]
+	}
+}
+
+func (p *Pool) Len() int {
+	return len(p.snapshots)
+}
+
+// Snapshots returns the snapshots, oldest first.
+func (p *Pool) Snapshots() []*Snapshot {
+	return p.snapshots
+}
+
+// Sample draws an opponent. The pool must not be empty.
+func (p *Pool) Sample() *Snapshot {
+	if p.sampling == Uniform {
+		return p.snapshots[rand.Intn(len(p.snapshots))]
+	}
+
+	total := 0.0
+	for _, s := range p.snapshots {
+		total += 1 - s.Score()
+	}
+	r := rand.Float64() * total
+	for _, s := range p.snapshots {
+		r -= 1 - s.Score()
+		if r < 0 {
+			return s
+		}
+	}
+	return p.snapshots[len(p.snapshots)-1]
+}
@@ -0,0 +1,48 @@
+package league
+
+import (
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// Test that snapshots do not change when the learner keeps learning
+func TestSnapshotFrozen(t *testing.T) {
+	learner := player.NewLearnerPlayer(1, 0.2, 0.1, "learner")
+	pool := NewPool(2, Uniform)
+	pool.Add(learner, 0)
+	for i := 0; i < 10; i++ {
+		game.NewGame(1, learner, player.NewRandomPlayer(2), true).Play()
+	}
+	if size := pool.Sample().Player.ModelSize(); size != 0 {
+		t.Errorf("snapshot of an untrained learner has %d states", size)
+	}
+
+	pool.Add(learner, 10)
+	pool.Add(learner, 20)
+	if pool.Len() != 2 || pool.Snapshots()[0].Games != 10 {
+		t.Errorf("pool of size 2 kept %d snapshots, oldest from game %d", pool.Len(), pool.Snapshots()[0].Games)
+	}
+}
+
+// Test that prioritized sampling favours snapshots the learner loses to
+func TestPrioritizedSampling(t *testing.T) {
+	learner := player.NewLearnerPlayer(1, 0.2, 0.1, "learner")
+	pool := NewPool(2, Prioritized)
+	pool.Add(learner, 0)
+	pool.Add(learner, 1)
+	pool.Snapshots()[0].Results = eval.Result{Wins: 99}
+	pool.Snapshots()[1].Results = eval.Result{Losses: 99}
+
+	hard := 0
+	for i := 0; i < 1000; i++ {
+		if pool.Sample() == pool.Snapshots()[1] {
+			hard++
+		}
+	}
+	if hard < 950 {
+		t.Errorf("drew the snapshot the learner always loses to %d times in 1000", hard)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// match plays a remote engine against a built-in AI player, half of the
+// games as X and half as O, and prints the engine's results.
+func match(args []string) {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	opts := gameFlags(fs)
+	games := fs.Int("games", 100, "number of games to play")
+	fs.Parse(args)
+
+	spec := opts.spec()
+	e := opts.environment()
+	opponent, err := spec.newAI(2)
+	if err != nil {
+		fmt.Println("Error creating AI player:", err)
+		os.Exit(2)
+	}
+	if lp, ok := opponent.(*player.LearnerPlayer); ok {
+		// a learner in player mode prints its move values
+		opponent = lp.Frozen()
+	}
+
+	engine, err := spec.newRemote(1)
+	if err != nil {
+		fmt.Println("Error starting engine:", err)
+		os.Exit(2)
+	}
+	defer engine.Close()
+
+	for seat := 1; seat <= 2; seat++ {
+		r := eval.Match(e, engine, opponent, seat, *games/2)
+		if err := engine.Err(); err != nil {
+			fmt.Println("Error playing engine:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Engine as %s: %d wins, %d draws, %d losses\n",
+			[]string{"", "X", "O"}[seat], r.Wins, r.Draws, r.Losses)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/mdp"
+	"github.com/param108/reinforcement-learning/tictactoe2/policy"
+)
+
+// solveMDP solves a gridworld exactly and, if an agent is chosen, compares
+// the policy the agent learns with the optimal one.
+func solveMDP(args []string) {
+	fs := flag.NewFlagSet("mdp", flag.ExitOnError)
+	layout := fs.String("map", "frozenlake4x4", "frozenlake4x4, frozenlake8x8 or a file with one row of the layout per line")
+	slip := fs.Float64("slip", -1, "probability of slipping sideways (default 2/3 for FrozenLake maps, 0 for files)")
+	stepReward := fs.Float64("step-reward", 0, "reward of every move")
+	holeReward := fs.Float64("hole-reward", 0, "reward of falling into a hole")
+	gamma := fs.Float64("gamma", 0.99, "discount factor")
+	agent := fs.String("agent", "", "tabular agent to train and compare: qlearning, sarsa or mc")
+	episodes := fs.Int("episodes", 20000, "training episodes")
+	epsilon := fs.Float64("epsilon", 0.1, "final exploration rate, reached halfway through training from 1")
+	alpha := fs.Float64("alpha", 0.1, "initial learning rate, falling linearly to a tenth of it")
+	fs.Parse(args)
+
+	cfg := mdp.GridConfig{GoalReward: 1}
+	switch *layout {
+	case "frozenlake4x4":
+		cfg = mdp.FrozenLake4x4
+	case "frozenlake8x8":
+		cfg = mdp.FrozenLake8x8
+	default:
+		data, err := os.ReadFile(*layout)
+		if err != nil {
+			fmt.Println("Error reading map:", err)
+			os.Exit(2)
+		}
+		cfg.Layout = strings.Fields(string(data))
+	}
+	if *slip >= 0 {
+		cfg.Slip = *slip
+	}
+	cfg.StepReward = *stepReward
+	cfg.HoleReward = *holeReward
+
+	g, err := mdp.NewGrid(cfg)
+	if err != nil {
+		fmt.Println("Invalid map:", err)
+		os.Exit(2)
+	}
+
+	actions, values := mdp.ValueIteration(g, *gamma, 1e-10)
+	fmt.Println("Optimal values and policy:")
+	g.Print(values, actions)
+
+	if *agent == "" {
+		return
+	}
+	opts := mdp.Options{
+		Episodes: *episodes,
+		MaxSteps: 10 * g.States(),
+		Gamma:    *gamma,
+		Epsilon:  policy.Linear{Start: 1, End: *epsilon, Episodes: *episodes / 2},
+		Alpha:    policy.Linear{Start: *alpha, End: *alpha / 10, Episodes: *episodes},
+	}
+	var q [][]float64
+	switch *agent {
+	case "qlearning":
+		q = mdp.QLearning(g, opts)
+	case "sarsa":
+		q = mdp.SARSA(g, opts)
+	case "mc":
+		opts.Alpha = nil
+		q = mdp.MonteCarlo(g, opts)
+	default:
+		fmt.Println("Unknown agent:", *agent)
+		os.Exit(2)
+	}
+
+	learned := mdp.GreedyActions(q)
+	learnedValues := mdp.EvaluatePolicy(g, mdp.Deterministic(g, learned), *gamma, 1e-10)
+	fmt.Printf("\nPolicy learned by %s in %d episodes and its true values:\n", *agent, *episodes)
+	g.Print(learnedValues, learned)
+	fmt.Printf("\nValue at the start: %.4f, optimal %.4f\n", learnedValues[g.Start()], values[g.Start()])
+}
@@ -0,0 +1,130 @@
+package mdp
+
+import (
+	"github.com/param108/reinforcement-learning/tictactoe2/policy"
+)
+
+// Options configures the tabular agents. Epsilon and Alpha are schedules
+// over the episode number. Monte Carlo control uses sample averages when
+// Alpha is nil.
+type Options struct {
+	Episodes int
+	MaxSteps int // Steps after which an episode is cut short
+	Gamma    float64
+	Epsilon  policy.Schedule
+	Alpha    policy.Schedule
+}
+
+func newQ(m MDP) [][]float64 {
+	q := make([][]float64, m.States())
+	for s := range q {
+		q[s] = make([]float64, m.Actions())
+	}
+	return q
+}
+
+// maxValue returns the highest value of q[s], or 0 if s is terminal.
+func maxValue(m MDP, q [][]float64, s int) float64 {
+	if stuck(m, s) {
+		return 0
+	}
+	best := q[s][0]
+	for _, v := range q[s][1:] {
+		if v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+// QLearning learns action values with off-policy TD control, acting
+// epsilon-greedily and bootstrapping from the greedy action.
+func QLearning(m MDP, opts Options) [][]float64 {
+	q := newQ(m)
+	for episode := 0; episode < opts.Episodes; episode++ {
+		epsilon, alpha := opts.Epsilon.At(episode), opts.Alpha.At(episode)
+		s := m.Start()
+		for step := 0; step < opts.MaxSteps && !stuck(m, s); step++ {
+			a := policy.EpsilonGreedy(q[s], epsilon)
+			next, reward := Step(m, s, a)
+			q[s][a] += alpha * (reward + opts.Gamma*maxValue(m, q, next) - q[s][a])
+			s = next
+		}
+	}
+	return q
+}
+
+// SARSA learns action values with on-policy TD control, bootstrapping from
+// the epsilon-greedy action taken next.
+func SARSA(m MDP, opts Options) [][]float64 {
+	q := newQ(m)
+	for episode := 0; episode < opts.Episodes; episode++ {
+		epsilon, alpha := opts.Epsilon.At(episode), opts.Alpha.At(episode)
+		s := m.Start()
+		a := policy.EpsilonGreedy(q[s], epsilon)
+		for step := 0; step < opts.MaxSteps && !stuck(m, s); step++ {
+			next, reward := Step(m, s, a)
+			target := reward
+			nextAction := 0
+			if !stuck(m, next) {
+				nextAction = policy.EpsilonGreedy(q[next], epsilon)
+				target += opts.Gamma * q[next][nextAction]
+			}
+			q[s][a] += alpha * (target - q[s][a])
+			s, a = next, nextAction
+		}
+	}
+	return q
+}
+
+// MonteCarlo learns action values with on-policy first-visit Monte Carlo
+// control, updating towards the return of each episode.
+func MonteCarlo(m MDP, opts Options) [][]float64 {
+	q := newQ(m)
+	visits := newQ(m)
+	type visit struct {
+		s, a   int
+		reward float64
+	}
+	for episode := 0; episode < opts.Episodes; episode++ {
+		epsilon := opts.Epsilon.At(episode)
+		trajectory := []visit{}
+		first := map[[2]int]int{}
+		s := m.Start()
+		for step := 0; step < opts.MaxSteps && !stuck(m, s); step++ {
+			a := policy.EpsilonGreedy(q[s], epsilon)
+			next, reward := Step(m, s, a)
+			if _, ok := first[[2]int{s, a}]; !ok {
+				first[[2]int{s, a}] = len(trajectory)
+			}
+			trajectory = append(trajectory, visit{s, a, reward})
+			s = next
+		}
+
+		g := 0.0
+		for i := len(trajectory) - 1; i >= 0; i-- {
+			v := trajectory[i]
+			g = v.reward + opts.Gamma*g
+			if first[[2]int{v.s, v.a}] != i {
+				continue
+			}
+			visits[v.s][v.a]++
+			step := 1 / visits[v.s][v.a]
+			if opts.Alpha != nil {
+				step = opts.Alpha.At(episode)
+			}
+			q[v.s][v.a] += step * (g - q[v.s][v.a])
+		}
+	}
+	return q
+}
+
+// GreedyActions returns the greedy action of q in each state, taking the
+// first of tied actions.
+func GreedyActions(q [][]float64) []int {
+	actions := make([]int, len(q))
+	for s := range q {
+		actions[s] = policy.Greedy(q[s])[0]
+	}
+	return actions
+}
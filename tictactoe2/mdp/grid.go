@@ -0,0 +1,224 @@
+package mdp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Grid actions, numbered as in FrozenLake.
+const (
+	Left = iota
+	Down
+	Right
+	Up
+)
+
+var moves = [4][2]int{{-1, 0}, {0, 1}, {1, 0}, {0, -1}}
+
+// Arrows draws each action.
+var Arrows = [4]string{"<", "v", ">", "^"}
+
+// GridConfig describes a gridworld. Layout has one string per row:
+//
+//	S  start
+//	.  floor (F also works, as in FrozenLake maps)
+//	#  wall, which blocks moves
+//	G  goal, a terminal cell paying GoalReward on entry
+//	H  hole, a terminal cell paying HoleReward on entry
+//
+// Every move pays StepReward plus the reward of the cell entered. With
+// probability Slip the agent slips and moves in one of the two directions
+// perpendicular to the one chosen instead. Moves off the grid or into a
+// wall leave the agent where it is.
+type GridConfig struct {
+	Layout     []string
+	Slip       float64
+	StepReward float64
+	GoalReward float64
+	HoleReward float64
+}
+
+// FrozenLake4x4 and FrozenLake8x8 are the FrozenLake maps, where the agent
+// moves as chosen a third of the time.
+var (
+	FrozenLake4x4 = GridConfig{
+		Layout:     []string{"SFFF", "FHFH", "FFFH", "HFFG"},
+		Slip:       2.0 / 3,
+		GoalReward: 1,
+	}
+	FrozenLake8x8 = GridConfig{
+		Layout: []string{
+			"SFFFFFFF",
+			"FFFFFFFF",
+			"FFFHFFFF",
+			"FFFFFHFF",
+			"FFFHFFFF",
+			"FHHFFFHF",
+			"FHFFHFHF",
+			"FFFHFFFG",
+		},
+		Slip:       2.0 / 3,
+		GoalReward: 1,
+	}
+)
+
+// Grid is a gridworld. State x + width*y is the agent at column x, row y.
+type Grid struct {
+	cfg         GridConfig
+	width       int
+	height      int
+	start       int
+	transitions [][][]Transition
+}
+
+// NewGrid builds the gridworld of cfg.
+func NewGrid(cfg GridConfig) (*Grid, error) {
+	if len(cfg.Layout) == 0 || len(cfg.Layout[0]) == 0 {
+		return nil, errors.New("the layout is empty")
+	}
+	if cfg.Slip < 0 || cfg.Slip > 1 {
+		return nil, fmt.Errorf("slip %g must be between 0 and 1", cfg.Slip)
+	}
+	g := &Grid{
+		cfg:    cfg,
+		width:  len(cfg.Layout[0]),
+		height: len(cfg.Layout),
+		start:  -1,
+	}
+	for y, row := range cfg.Layout {
+		if len(row) != g.width {
+			return nil, fmt.Errorf("row %d has %d cells, want %d", y, len(row), g.width)
+		}
+		for x, c := range row {
+			if !strings.ContainsRune("S.F#GH", c) {
+				return nil, fmt.Errorf("unknown cell %q at %d,%d", c, x, y)
+			}
+			if c == 'S' {
+				if g.start >= 0 {
+					return nil, errors.New("the layout has more than one start")
+				}
+				g.start = x + g.width*y
+			}
+		}
+	}
+	if g.start < 0 {
+		return nil, errors.New("the layout has no start")
+	}
+
+	g.transitions = make([][][]Transition, g.States())
+	for s := range g.transitions {
+		if g.Terminal(s) || g.cell(s) == '#' {
+			continue
+		}
+		g.transitions[s] = make([][]Transition, 4)
+		for a := range moves {
+			g.transitions[s][a] = g.outcomes(s, a)
+		}
+	}
+	return g, nil
+}
+
+func (g *Grid) Width() int {
+	return g.width
+}
+
+func (g *Grid) Height() int {
+	return g.height
+}
+
+func (g *Grid) cell(s int) byte {
+	return g.cfg.Layout[s/g.width][s%g.width]
+}
+
+// move returns the state reached by moving from s in direction a.
+func (g *Grid) move(s, a int) int {
+	x := s%g.width + moves[a][0]
+	y := s/g.width + moves[a][1]
+	if x < 0 || x >= g.width || y < 0 || y >= g.height {
+		return s
+	}
+	next := x + g.width*y
+	if g.cell(next) == '#' {
+		return s
+	}
+	return next
+}
+
+// outcomes returns the transitions of taking a in s, merging directions
+// that reach the same state.
+func (g *Grid) outcomes(s, a int) []Transition {
+	probs := map[int]float64{}
+	probs[g.move(s, a)] += 1 - g.cfg.Slip
+	if g.cfg.Slip > 0 {
+		probs[g.move(s, (a+1)%4)] += g.cfg.Slip / 2
+		probs[g.move(s, (a+3)%4)] += g.cfg.Slip / 2
+	}
+
+	transitions := []Transition{}
+	for _, d := range []int{a, (a + 1) % 4, (a + 3) % 4} {
+		next := g.move(s, d)
+		if p, ok := probs[next]; ok && p > 0 {
+			transitions = append(transitions, Transition{Prob: p, Next: next, Reward: g.reward(next)})
+			delete(probs, next)
+		}
+	}
+	return transitions
+}
+
+// reward returns the reward of a move into s.
+func (g *Grid) reward(s int) float64 {
+	switch g.cell(s) {
+	case 'G':
+		return g.cfg.StepReward + g.cfg.GoalReward
+	case 'H':
+		return g.cfg.StepReward + g.cfg.HoleReward
+	}
+	return g.cfg.StepReward
+}
+
+func (g *Grid) States() int {
+	return g.width * g.height
+}
+
+func (g *Grid) Actions() int {
+	return len(moves)
+}
+
+func (g *Grid) Start() int {
+	return g.start
+}
+
+// Terminal reports whether s is a goal or a hole. Wall cells are never
+// entered and have no transitions either.
+func (g *Grid) Terminal(s int) bool {
+	c := g.cell(s)
+	return c == 'G' || c == 'H'
+}
+
+func (g *Grid) Transitions(s, a int) []Transition {
+	if g.transitions[s] == nil {
+		return nil
+	}
+	return g.transitions[s][a]
+}
+
+// Print prints the layout with the value of each state and the action of
+// the policy in it, if they are given.
+func (g *Grid) Print(values []float64, actions []int) {
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			s := x + g.width*y
+			c := string(g.cell(s))
+			if actions != nil && g.transitions[s] != nil {
+				c = Arrows[actions[s]]
+			}
+			if values != nil {
+				fmt.Printf(" %s %6.3f", c, values[s])
+			} else {
+				fmt.Printf(" %s", c)
+			}
+		}
+		fmt.Println()
+	}
+}
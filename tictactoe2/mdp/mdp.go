@@ -0,0 +1,53 @@
+// Package mdp implements single-agent Markov decision processes with
+// known dynamics, exact dynamic programming solvers for them and tabular
+// agents that learn them from experience. The exact solutions are ground
+// truth to validate the learners against.
+package mdp
+
+import (
+	"math/rand"
+)
+
+// Transition is one possible outcome of taking an action.
+type Transition struct {
+	Prob   float64
+	Next   int
+	Reward float64
+}
+
+// MDP is a finite Markov decision process. States and actions are
+// numbered from 0. Terminal states have no transitions.
+type MDP interface {
+	States() int
+	Actions() int
+	Start() int
+	Terminal(s int) bool
+	Transitions(s, a int) []Transition
+}
+
+// Step samples the outcome of taking a in s.
+func Step(m MDP, s, a int) (next int, reward float64) {
+	r := rand.Float64()
+	transitions := m.Transitions(s, a)
+	for _, t := range transitions {
+		r -= t.Prob
+		if r < 0 {
+			return t.Next, t.Reward
+		}
+	}
+	last := transitions[len(transitions)-1]
+	return last.Next, last.Reward
+}
+
+// Policy gives the probability of each action in each state.
+type Policy [][]float64
+
+// Deterministic returns the policy that takes actions[s] in state s.
+func Deterministic(m MDP, actions []int) Policy {
+	pi := make(Policy, m.States())
+	for s := range pi {
+		pi[s] = make([]float64, m.Actions())
+		pi[s][actions[s]] = 1
+	}
+	return pi
+}
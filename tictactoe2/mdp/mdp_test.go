@@ -0,0 +1,96 @@
+package mdp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/policy"
+)
+
+func newGrid(t *testing.T, cfg GridConfig) *Grid {
+	g, err := NewGrid(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// Test the solvers on a corridor whose values are known in closed form
+func TestCorridor(t *testing.T) {
+	g := newGrid(t, GridConfig{Layout: []string{"S.#.G"}, GoalReward: 1})
+	// the wall blocks the corridor, so the goal cannot be reached
+	for _, solve := range []func(MDP, float64, float64) ([]int, []float64){PolicyIteration, ValueIteration} {
+		if _, v := solve(g, 0.9, 1e-9); v[g.Start()] != 0 {
+			t.Errorf("blocked corridor: V(start) = %g, want 0", v[g.Start()])
+		}
+	}
+
+	g = newGrid(t, GridConfig{Layout: []string{"S..G"}, GoalReward: 1})
+	for _, solve := range []func(MDP, float64, float64) ([]int, []float64){PolicyIteration, ValueIteration} {
+		actions, v := solve(g, 0.9, 1e-9)
+		if math.Abs(v[g.Start()]-0.81) > 1e-6 {
+			t.Errorf("V(start) = %g, want 0.81", v[g.Start()])
+		}
+		if actions[g.Start()] != Right {
+			t.Errorf("action at the start = %s, want >", Arrows[actions[g.Start()]])
+		}
+	}
+}
+
+// Test that policy iteration and value iteration agree on FrozenLake
+func TestFrozenLake(t *testing.T) {
+	g := newGrid(t, FrozenLake4x4)
+	piActions, piValues := PolicyIteration(g, 0.99, 1e-10)
+	viActions, viValues := ValueIteration(g, 0.99, 1e-10)
+	for s := range piValues {
+		if math.Abs(piValues[s]-viValues[s]) > 1e-6 {
+			t.Errorf("state %d: policy iteration %g, value iteration %g", s, piValues[s], viValues[s])
+		}
+	}
+	for _, actions := range [][]int{piActions, viActions} {
+		v := EvaluatePolicy(g, Deterministic(g, actions), 0.99, 1e-10)
+		if math.Abs(v[g.Start()]-viValues[g.Start()]) > 1e-6 {
+			t.Errorf("policy is worth %g at the start, want %g", v[g.Start()], viValues[g.Start()])
+		}
+	}
+	if math.Abs(viValues[g.Start()]-0.542) > 0.001 {
+		t.Errorf("V(start) = %g, want 0.542", viValues[g.Start()])
+	}
+}
+
+// Test that the learners find a policy close to optimal on a slippery grid
+func TestAgents(t *testing.T) {
+	g := newGrid(t, GridConfig{
+		Layout:     []string{"S...", ".#.H", "...G"},
+		Slip:       0.2,
+		StepReward: -0.04,
+		GoalReward: 1,
+		HoleReward: -1,
+	})
+	gamma := 0.95
+	_, optimal := ValueIteration(g, gamma, 1e-10)
+
+	opts := Options{
+		Episodes: 20000,
+		MaxSteps: 100,
+		Gamma:    gamma,
+		Epsilon:  policy.Linear{Start: 1, End: 0.1, Episodes: 10000},
+		Alpha:    policy.Linear{Start: 0.2, End: 0.01, Episodes: 20000},
+	}
+	agents := map[string]func(MDP, Options) [][]float64{
+		"Q-learning":  QLearning,
+		"SARSA":       SARSA,
+		"Monte Carlo": MonteCarlo,
+	}
+	for name, learn := range agents {
+		opts := opts
+		if name == "Monte Carlo" {
+			opts.Alpha = nil // sample averages
+		}
+		actions := GreedyActions(learn(g, opts))
+		v := EvaluatePolicy(g, Deterministic(g, actions), gamma, 1e-10)
+		if v[g.Start()] < optimal[g.Start()]-0.05 {
+			t.Errorf("%s: learned policy is worth %g at the start, optimal %g", name, v[g.Start()], optimal[g.Start()])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package mdp
+
+import (
+	"math"
+)
+
+// The solvers sweep every state until no value changes by more than
+// theta. They need gamma < 1 unless every policy they meet reaches a
+// terminal state.
+
+// stuck reports whether s has no actions: it is terminal or can never be
+// entered, like a wall. Its value is 0.
+func stuck(m MDP, s int) bool {
+	return m.Terminal(s) || len(m.Transitions(s, 0)) == 0
+}
+
+// ActionValue returns the expected return of taking a in s and then
+// following values v.
+func ActionValue(m MDP, v []float64, gamma float64, s, a int) float64 {
+	q := 0.0
+	for _, t := range m.Transitions(s, a) {
+		q += t.Prob * (t.Reward + gamma*v[t.Next])
+	}
+	return q
+}
+
+// ActionValues returns the action values q(s, a) of values v.
+func ActionValues(m MDP, v []float64, gamma float64) [][]float64 {
+	q := make([][]float64, m.States())
+	for s := range q {
+		q[s] = make([]float64, m.Actions())
+		if stuck(m, s) {
+			continue
+		}
+		for a := range q[s] {
+			q[s][a] = ActionValue(m, v, gamma, s, a)
+		}
+	}
+	return q
+}
+
+// EvaluatePolicy returns the state values of pi by iterative policy
+// evaluation.
+func EvaluatePolicy(m MDP, pi Policy, gamma, theta float64) []float64 {
+	v := make([]float64, m.States())
+	for {
+		delta := 0.0
+		for s := range v {
+			if stuck(m, s) {
+				continue
+			}
+			value := 0.0
+			for a, p := range pi[s] {
+				if p > 0 {
+					value += p * ActionValue(m, v, gamma, s, a)
+				}
+			}
+			delta = math.Max(delta, math.Abs(value-v[s]))
+			v[s] = value
+		}
+		if delta < theta {
+			return v
+		}
+	}
+}
+
+// greedy returns the first action with the highest value in s, and the value.
+func greedy(m MDP, v []float64, gamma float64, s int) (int, float64) {
+	best, bestValue := 0, math.Inf(-1)
+	for a := 0; a < m.Actions(); a++ {
+		if q := ActionValue(m, v, gamma, s, a); q > bestValue+1e-12 {
+			best, bestValue = a, q
+		}
+	}
+	return best, bestValue
+}
+
+// PolicyIteration returns an optimal deterministic policy, as the action
+// to take in each state, and its values. It alternates policy evaluation
+// and greedy improvement until the policy is stable.
+func PolicyIteration(m MDP, gamma, theta float64) ([]int, []float64) {
+	actions := make([]int, m.States())
+	for {
+		v := EvaluatePolicy(m, Deterministic(m, actions), gamma, theta)
+		stable := true
+		for s := range actions {
+			if stuck(m, s) {
+				continue
+			}
+			// only switch for a clear improvement, so ties between
+			// equally good actions cannot make the policy cycle
+			best, bestValue := greedy(m, v, gamma, s)
+			if bestValue > ActionValue(m, v, gamma, s, actions[s])+theta {
+				actions[s] = best
+				stable = false
+			}
+		}
+		if stable {
+			return actions, v
+		}
+	}
+}
+
+// ValueIteration returns the optimal values and a greedy policy for them.
+func ValueIteration(m MDP, gamma, theta float64) ([]int, []float64) {
+	v := make([]float64, m.States())
+	for {
+		delta := 0.0
+		for s := range v {
+			if stuck(m, s) {
+				continue
+			}
+			_, value := greedy(m, v, gamma, s)
+			delta = math.Max(delta, math.Abs(value-v[s]))
+			v[s] = value
+		}
+		if delta < theta {
+			break
+		}
+	}
+
+	actions := make([]int, m.States())
+	for s := range actions {
+		if !stuck(m, s) {
+			actions[s], _ = greedy(m, v, gamma, s)
+		}
+	}
+	return actions, v
+}
@@ -0,0 +1,236 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Record is a snapshot of training progress.
+type Record struct {
+	Games          int     `json:"games"`    // Games played so far
+	Phase          string  `json:"phase"`    // Training phase, e.g. "X vs minimax"
+	WinRate        float64 `json:"win_rate"` // Win, draw and loss rates over the last window of games
+	DrawRate       float64 `json:"draw_rate"`
+	LossRate       float64 `json:"loss_rate"`
+	TDError        float64 `json:"td_error"`   // Mean absolute TD error since the previous record
+	ModelSize      int     `json:"model_size"` // Number of states in the model
+	Epsilon        float64 `json:"epsilon"`    // Exploration rate
+	LearningRate   float64 `json:"learning_rate"`
+	GamesPerSecond float64 `json:"games_per_second"` // Throughput since the previous record
+}
+
+var header = []string{
+	"games", "phase", "win_rate", "draw_rate", "loss_rate", "td_error",
+	"model_size", "epsilon", "learning_rate", "games_per_second",
+}
+
+func (r Record) fields() []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', 6, 64) }
+	return []string{
+		strconv.Itoa(r.Games), r.Phase, f(r.WinRate), f(r.DrawRate), f(r.LossRate),
+		f(r.TDError), strconv.Itoa(r.ModelSize), f(r.Epsilon), f(r.LearningRate),
+		f(r.GamesPerSecond),
+	}
+}
+
+// Writer writes training records to a file.
+type Writer interface {
+	Write(r Record) error
+	Close() error
+}
+
+// NewWriter creates path and returns a writer for it. The format is chosen
+// from the extension: ".csv" for CSV, ".jsonl" for JSON lines.
+func NewWriter(path string) (Writer, error) {
+	ext := filepath.Ext(path)
+	if ext != ".csv" && ext != ".jsonl" {
+		return nil, errors.New("unknown metrics format: " + path + " (use .csv or .jsonl)")
+	}
+
+	fp, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if ext == ".jsonl" {
+		return &jsonlWriter{fp: fp, enc: json.NewEncoder(fp)}, nil
+	}
+
+	w := &csvWriter{fp: fp, w: csv.NewWriter(fp)}
+	if err := w.w.Write(header); err != nil {
+		fp.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+type csvWriter struct {
+	fp *os.File
+	w  *csv.Writer
+}
+
+func (w *csvWriter) Write(r Record) error {
+	if err := w.w.Write(r.fields()); err != nil {
+		return err
+	}
+	// flush every record so partial runs can be charted
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		w.fp.Close()
+		return err
+	}
+	return w.fp.Close()
+}
+
+type jsonlWriter struct {
+	fp  *os.File
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) Write(r Record) error {
+	return w.enc.Encode(r)
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.fp.Close()
+}
+
+// Window keeps the outcomes of the last size games.
+type Window struct {
+	results []int // 1 win, 0 draw, -1 loss
+	next    int
+	full    bool
+}
+
+func NewWindow(size int) *Window {
+	return &Window{
+		results: make([]int, size),
+	}
+}
+
+// Add records the outcome of a game, dropping the oldest one if the window is full.
+func (w *Window) Add(result int) {
+	w.results[w.next] = result
+	w.next++
+	if w.next == len(w.results) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Rates returns the win, draw and loss rates over the games in the window.
+func (w *Window) Rates() (float64, float64, float64) {
+	n := w.next
+	if w.full {
+		n = len(w.results)
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+
+	var wins, draws, losses int
+	for _, r := range w.results[:n] {
+		switch r {
+		case 1:
+			wins++
+		case 0:
+			draws++
+		default:
+			losses++
+		}
+	}
+	total := float64(n)
+	return float64(wins) / total, float64(draws) / total, float64(losses) / total
+}
+
+// Read loads the records written by a Writer. The format is chosen from the
+// extension as in NewWriter.
+func Read(path string) ([]Record, error) {
+	ext := filepath.Ext(path)
+	if ext != ".csv" && ext != ".jsonl" {
+		return nil, errors.New("unknown metrics format: " + path + " (use .csv or .jsonl)")
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	records := []Record{}
+	if ext == ".jsonl" {
+		dec := json.NewDecoder(fp)
+		for {
+			var r Record
+			if err := dec.Decode(&r); err == io.EOF {
+				return records, nil
+			} else if err != nil {
+				return nil, err
+			}
+			records = append(records, r)
+		}
+	}
+
+	rows, err := csv.NewReader(fp).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		if i == 0 {
+			continue // header
+		}
+		r, err := parseFields(row)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, i+1, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func parseFields(row []string) (Record, error) {
+	if len(row) != len(header) {
+		return Record{}, fmt.Errorf("expected %d fields, got %d", len(header), len(row))
+	}
+
+	var err error
+	i := func(s string) int {
+		v, e := strconv.Atoi(s)
+		if e != nil && err == nil {
+			err = e
+		}
+		return v
+	}
+	f := func(s string) float64 {
+		v, e := strconv.ParseFloat(s, 64)
+		if e != nil && err == nil {
+			err = e
+		}
+		return v
+	}
+
+	r := Record{
+		Games:          i(row[0]),
+		Phase:          row[1],
+		WinRate:        f(row[2]),
+		DrawRate:       f(row[3]),
+		LossRate:       f(row[4]),
+		TDError:        f(row[5]),
+		ModelSize:      i(row[6]),
+		Epsilon:        f(row[7]),
+		LearningRate:   f(row[8]),
+		GamesPerSecond: f(row[9]),
+	}
+	return r, err
+}
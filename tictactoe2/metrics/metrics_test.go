@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test the rates over a window before and after it wraps around
+func TestWindowRates(t *testing.T) {
+	w := NewWindow(4)
+	rates := func() [3]float64 {
+		win, draw, loss := w.Rates()
+		return [3]float64{win, draw, loss}
+	}
+	if got := rates(); got != [3]float64{0, 0, 0} {
+		t.Errorf("empty window: rates %v; want all 0", got)
+	}
+
+	w.Add(1)
+	w.Add(0)
+	if got, want := rates(), [3]float64{0.5, 0.5, 0}; got != want {
+		t.Errorf("2 games: rates %v; want %v", got, want)
+	}
+
+	w.Add(-1)
+	w.Add(1)
+	if got, want := rates(), [3]float64{0.5, 0.25, 0.25}; got != want {
+		t.Errorf("full window: rates %v; want %v", got, want)
+	}
+
+	// the two oldest games, a win and a draw, are dropped
+	w.Add(-1)
+	w.Add(-1)
+	if got, want := rates(), [3]float64{0.25, 0, 0.75}; got != want {
+		t.Errorf("after wrapping: rates %v; want %v", got, want)
+	}
+}
+
+// Test that records written in either format are read back unchanged
+func TestWriteRead(t *testing.T) {
+	records := []Record{
+		{Games: 100, Phase: "X vs minimax", WinRate: 0.25, DrawRate: 0.5, LossRate: 0.25,
+			TDError: 0.125, ModelSize: 420, Epsilon: 0.1, LearningRate: 0.2, GamesPerSecond: 1500},
+		{Games: 200, Phase: "self-play, O", WinRate: 0, DrawRate: 1, LossRate: 0,
+			TDError: 0.0625, ModelSize: 613, Epsilon: 0.05, LearningRate: 0.01, GamesPerSecond: 2048.5},
+	}
+	for _, ext := range []string{".csv", ".jsonl"} {
+		path := filepath.Join(t.TempDir(), "metrics"+ext)
+		w, err := NewWriter(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range records {
+			if err := w.Write(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := Read(path)
+		if err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		if !reflect.DeepEqual(got, records) {
+			t.Errorf("%s: read %+v; want %+v", ext, got, records)
+		}
+
+		if ext == ".csv" {
+			fp, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			line, _ := bufio.NewReader(fp).ReadString('\n')
+			fp.Close()
+			if want := strings.Join(header, ",") + "\n"; line != want {
+				t.Errorf("csv header %q; want %q", line, want)
+			}
+		}
+	}
+}
+
+// Test that files without records read as no records
+func TestReadEmpty(t *testing.T) {
+	for _, ext := range []string{".csv", ".jsonl"} {
+		dir := t.TempDir()
+
+		// a file that was created but never written to
+		empty := filepath.Join(dir, "empty"+ext)
+		if err := os.WriteFile(empty, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		// a file that was closed before the first record
+		unused := filepath.Join(dir, "unused"+ext)
+		w, err := NewWriter(unused)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, path := range []string{empty, unused} {
+			got, err := Read(path)
+			if err != nil {
+				t.Errorf("%s: %v", filepath.Base(path), err)
+			} else if len(got) != 0 {
+				t.Errorf("%s: read %d records; want none", filepath.Base(path), len(got))
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package nim implements Nim and subtraction games. Players take turns
+// removing counters from one heap; under normal play the player who cannot
+// move loses, under misère play the player who takes the last counter loses.
+package nim
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Encoding selects how State.Key writes a state.
+type Encoding int
+
+const (
+	// Heaps writes every heap in order.
+	Heaps Encoding = iota
+	// Sorted writes the heaps in ascending order. Heap order does not
+	// change the game, so positions that only differ by it share a key.
+	Sorted
+)
+
+// Config describes a game.
+type Config struct {
+	Heaps    []int // Starting heap sizes
+	Take     []int // Counters a move may take from a heap; empty means any number (Nim)
+	Misere   bool  // Whether taking the last counter loses
+	Encoding Encoding
+}
+
+// Validate returns an error if no game can be played with c.
+func (c Config) Validate() error {
+	if len(c.Heaps) == 0 {
+		return errors.New("at least one heap is needed")
+	}
+	for _, h := range c.Heaps {
+		if h < 0 {
+			return fmt.Errorf("heap size %d is negative", h)
+		}
+	}
+	for _, t := range c.Take {
+		if t < 1 {
+			return fmt.Errorf("move size %d must be positive", t)
+		}
+	}
+	if c.Misere && len(c.Take) > 0 {
+		return errors.New("misère play is only supported for Nim, without a subtraction set")
+	}
+	return nil
+}
+
+// Environment creates games of one configuration.
+type Environment struct {
+	Config Config
+}
+
+func (e Environment) Name() string {
+	name := "nim"
+	if len(e.Config.Take) > 0 {
+		name = "subtraction"
+	}
+	if e.Config.Misere {
+		name += " misere"
+	}
+	return name
+}
+
+func (e Environment) NewState(start int) env.State {
+	return New(e.Config, start)
+}
+
+// State is a position. Action h*stride+n takes n counters from heap h,
+// where stride is one more than the largest starting heap.
+type State struct {
+	cfg    *Config
+	stride int
+	heaps  []int
+	start  int
+	player int
+}
+
+// New creates the starting position of cfg. cfg must be valid.
+func New(cfg Config, start int) *State {
+	stride := 1
+	for _, h := range cfg.Heaps {
+		if h+1 > stride {
+			stride = h + 1
+		}
+	}
+	heaps := make([]int, len(cfg.Heaps))
+	copy(heaps, cfg.Heaps)
+	return &State{
+		cfg:    &cfg,
+		stride: stride,
+		heaps:  heaps,
+		start:  start,
+		player: start,
+	}
+}
+
+// Heaps returns a copy of the heap sizes.
+func (s *State) Heaps() []int {
+	heaps := make([]int, len(s.heaps))
+	copy(heaps, s.heaps)
+	return heaps
+}
+
+// ActionFor returns the action taking n counters from heap h.
+func (s *State) ActionFor(h, n int) env.Action {
+	return env.Action(h*s.stride + n)
+}
+
+func (s *State) split(a env.Action) (int, int) {
+	return int(a) / s.stride, int(a) % s.stride
+}
+
+func (s *State) Player() int {
+	return s.player
+}
+
+// allowed reports whether a move may take n counters from a heap of size heap.
+func (s *State) allowed(heap, n int) bool {
+	if n < 1 || n > heap {
+		return false
+	}
+	if len(s.cfg.Take) == 0 {
+		return true
+	}
+	for _, t := range s.cfg.Take {
+		if t == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *State) Actions() []env.Action {
+	actions := []env.Action{}
+	for h, heap := range s.heaps {
+		for n := 1; n <= heap; n++ {
+			if s.allowed(heap, n) {
+				actions = append(actions, s.ActionFor(h, n))
+			}
+		}
+	}
+	return actions
+}
+
+func (s *State) Apply(a env.Action) (env.State, bool) {
+	h, n := s.split(a)
+	if a < 0 || h >= len(s.heaps) || !s.allowed(s.heaps[h], n) {
+		return nil, false
+	}
+	next := *s
+	next.heaps = s.Heaps()
+	next.heaps[h] -= n
+	next.player = 3 - s.player
+	return &next, true
+}
+
+func (s *State) Winner() int {
+	if len(s.Actions()) > 0 {
+		return 0
+	}
+	if s.cfg.Misere {
+		// the previous player took the last counter
+		return s.player
+	}
+	// the player to move cannot move
+	return 3 - s.player
+}
+
+func (s *State) Reward(player int) float64 {
+	return env.TerminalReward(s.Winner(), player)
+}
+
+// ConfigKey identifies the configuration, for env.Configured.
+func (s *State) ConfigKey() string {
+	return fmt.Sprintf("%+v", *s.cfg)
+}
+
+// Key writes the heaps as the configured Encoding selects, followed by the
+// start player and the player to move.
+func (s *State) Key() string {
+	heaps := s.Heaps()
+	if s.cfg.Encoding == Sorted {
+		sort.Ints(heaps)
+	}
+	parts := make([]string, len(heaps))
+	for i, h := range heaps {
+		parts[i] = strconv.Itoa(h)
+	}
+	return fmt.Sprintf("%s/%d%d", strings.Join(parts, ","), s.start, s.player)
+}
+
+// FormatAction formats a as "heap counters".
+func (s *State) FormatAction(a env.Action) string {
+	h, n := s.split(a)
+	return fmt.Sprintf("%d %d", h, n)
+}
+
+// ParseAction parses a move written as "heap counters".
+func (s *State) ParseAction(text string) (env.Action, error) {
+	parts := strings.Fields(text)
+	if len(parts) != 2 {
+		return 0, errors.New("please enter the heap and the number of counters to take")
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	n, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || h < 0 || h >= len(s.heaps) {
+		return 0, fmt.Errorf("enter a heap between 0 and %d", len(s.heaps)-1)
+	}
+	if !s.allowed(s.heaps[h], n) {
+		if len(s.cfg.Take) > 0 {
+			return 0, fmt.Errorf("you may take %v counters, at most %d from heap %d", s.cfg.Take, s.heaps[h], h)
+		}
+		return 0, fmt.Errorf("take between 1 and %d counters from heap %d", s.heaps[h], h)
+	}
+	return s.ActionFor(h, n), nil
+}
+
+func (s *State) Print() {
+	fmt.Println("Heaps:")
+	for h, heap := range s.heaps {
+		fmt.Printf("%d: %-3d %s\n", h, heap, strings.Repeat("|", heap))
+	}
+	fmt.Println("Next Player:", s.player)
+}
@@ -0,0 +1,104 @@
+package nim
+
+import (
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+var configs = []Config{
+	{Heaps: []int{3, 4, 5}},
+	{Heaps: []int{1, 2, 3, 4}, Misere: true},
+	{Heaps: []int{1, 1, 3}, Misere: true},
+	{Heaps: []int{10}, Take: []int{1, 2, 3}},
+	{Heaps: []int{7, 9}, Take: []int{1, 3, 4}},
+}
+
+// walk calls f on every position reachable from s.
+func walk(s *State, f func(s *State)) {
+	f(s)
+	for _, a := range s.Actions() {
+		next, _ := s.Apply(a)
+		walk(next.(*State), f)
+	}
+}
+
+// Test that the nim-sum agrees with a full minimax search
+func TestWinningMatchesMinimax(t *testing.T) {
+	for _, cfg := range configs {
+		minimax := [3]*player.MinimaxPlayer{nil, player.NewMinimaxPlayer(1), player.NewMinimaxPlayer(2)}
+		walk(New(cfg, 1), func(s *State) {
+			if s.Winner() != 0 {
+				return
+			}
+			_, value := minimax[s.Player()].OptimalMoves(s)
+			if Winning(s) != (value == 1) {
+				t.Errorf("%s %v: Winning = %v, minimax value %d", Environment{cfg}.Name(), s.heaps, Winning(s), value)
+			}
+		})
+	}
+}
+
+// Test that the optimal player always beats a random player from a won position
+func TestOptimalPlayer(t *testing.T) {
+	for _, cfg := range configs {
+		start := New(cfg, 1)
+		seat := 1
+		if !Winning(start) {
+			seat = 2
+		}
+		players := [3]player.Player{}
+		players[seat] = NewOptimalPlayer(seat)
+		players[3-seat] = player.NewRandomPlayer(3 - seat)
+		for i := 0; i < 50; i++ {
+			if got := game.NewGameState(start, players[1], players[2], true).Play(); got != seat {
+				t.Fatalf("%s %v: winner %d; want %d", Environment{cfg}.Name(), cfg.Heaps, got, seat)
+			}
+		}
+	}
+}
+
+// Test that the TD updates in LearnerPlayer.Win and Lose learn the optimal
+// moves of a small Nim game from playing the optimal player. The learner
+// moves first from a won position, so it is checked in every won position
+// it can reach by playing well.
+func TestLearnerLearnsNim(t *testing.T) {
+	for _, encoding := range []Encoding{Heaps, Sorted} {
+		cfg := Config{Heaps: []int{2, 3, 4}, Encoding: encoding}
+		learner := player.NewLearnerPlayer(1, 0.3, 0.2, "learner")
+		for i := 0; i < 3000; i++ {
+			game.NewGameState(New(cfg, 1), learner, NewOptimalPlayer(2), true).Play()
+		}
+
+		frozen := learner.Frozen()
+		var check func(s *State)
+		check = func(s *State) {
+			if s.Winner() != 0 {
+				return
+			}
+			if s.Player() == 2 {
+				for _, a := range s.Actions() {
+					next, _ := s.Apply(a)
+					check(next.(*State))
+				}
+				return
+			}
+			optimal := map[env.Action]bool{}
+			for _, a := range OptimalMoves(s) {
+				optimal[a] = true
+			}
+			for _, a := range frozen.GreedyMoves(s) {
+				if !optimal[a] {
+					t.Errorf("encoding %d %v: learner plays %s, which loses", encoding, s.heaps, s.FormatAction(a))
+				}
+			}
+			for a := range optimal {
+				next, _ := s.Apply(a)
+				check(next.(*State))
+			}
+		}
+		check(New(cfg, 1))
+	}
+}
@@ -0,0 +1,109 @@
+package nim
+
+import (
+	"math/rand"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Grundy returns the Grundy value of a single heap of size heap when a move
+// may take any of take counters (any number if take is empty). A position
+// of several heaps is lost for the player to move, under normal play,
+// exactly when the XOR of the heaps' Grundy values (the nim-sum) is zero.
+func Grundy(heap int, take []int) int {
+	if len(take) == 0 {
+		return heap
+	}
+
+	g := make([]int, heap+1)
+	for n := 1; n <= heap; n++ {
+		seen := map[int]bool{}
+		for _, t := range take {
+			if t <= n {
+				seen[g[n-t]] = true
+			}
+		}
+		for seen[g[n]] {
+			g[n]++
+		}
+	}
+	return g[heap]
+}
+
+// NimSum returns the XOR of the Grundy values of the heaps of s.
+func NimSum(s *State) int {
+	sum := 0
+	for _, h := range s.heaps {
+		sum ^= Grundy(h, s.cfg.Take)
+	}
+	return sum
+}
+
+// Winning reports whether the player to move in s can force a win.
+func Winning(s *State) bool {
+	if s.cfg.Misere {
+		// misère Nim plays as normal Nim until every heap has at most one
+		// counter, when the player to move wants an odd number of heaps left
+		big, ones := 0, 0
+		for _, h := range s.heaps {
+			if h > 1 {
+				big++
+			} else if h == 1 {
+				ones++
+			}
+		}
+		if big == 0 {
+			return ones%2 == 0
+		}
+	}
+	return NimSum(s) != 0
+}
+
+// OptimalPlayer plays perfectly using the nim-sum. In a lost position it
+// takes a random legal move.
+type OptimalPlayer struct {
+	player int
+}
+
+func NewOptimalPlayer(player int) *OptimalPlayer {
+	return &OptimalPlayer{
+		player: player,
+	}
+}
+
+func (p *OptimalPlayer) GetPlayer() int {
+	return p.player
+}
+
+func (p *OptimalPlayer) SetPlayer(player int) {
+	p.player = player
+}
+
+// OptimalMoves returns every move of s that leaves the opponent in a lost
+// position. It is empty if s is lost.
+func OptimalMoves(s *State) []env.Action {
+	best := []env.Action{}
+	for _, a := range s.Actions() {
+		next, _ := s.Apply(a)
+		n := next.(*State)
+		if n.Winner() == s.player || (n.Winner() == 0 && !Winning(n)) {
+			best = append(best, a)
+		}
+	}
+	return best
+}
+
+func (p *OptimalPlayer) MakeMove(state env.State) env.Action {
+	s := state.(*State)
+	moves := OptimalMoves(s)
+	if len(moves) == 0 {
+		moves = s.Actions()
+	}
+	return moves[rand.Intn(len(moves))]
+}
+
+func (p *OptimalPlayer) Win() {
+}
+
+func (p *OptimalPlayer) Lose() {
+}
@@ -0,0 +1,57 @@
+//go:build ignore
+
+// generate solves every position of standard tic-tac-toe by minimax and
+// writes the table of package oracle to tictactoe.csv.
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/statespace"
+)
+
+func main() {
+	fp, err := os.Create("tictactoe.csv")
+	if err != nil {
+		fmt.Println("Error creating table:", err)
+		os.Exit(1)
+	}
+	defer fp.Close()
+
+	w := csv.NewWriter(fp)
+	w.Write([]string{"position", "key", "value", "optimal"})
+
+	solvers := [3]*player.MinimaxPlayer{nil, player.NewMinimaxPlayer(1), player.NewMinimaxPlayer(2)}
+	for start := 1; start <= 2; start++ {
+		for _, pos := range statespace.Enumerate(board.NewBoard(start)).Positions {
+			b := pos.State.(*board.Board)
+			value, moves := 0, []string{}
+			switch b.Winner() {
+			case 0:
+				best, v := solvers[b.Player()].OptimalMoves(b)
+				for _, a := range best {
+					x, y := b.Coords(a)
+					moves = append(moves, board.CellName(board.TicTacToe, x, y))
+				}
+				value = v
+			case 3:
+			case b.Player():
+				value = 1
+			default:
+				value = -1
+			}
+			w.Write([]string{b.String(), b.Key(), map[int]string{1: "win", 0: "draw", -1: "loss"}[value], strings.Join(moves, " ")})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Println("Error writing table:", err)
+		os.Exit(1)
+	}
+}
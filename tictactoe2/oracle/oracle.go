@@ -0,0 +1,94 @@
+// Package oracle knows perfect play of tic-tac-toe on the standard 3x3
+// board. Every position reachable from the empty board, with either player
+// starting, was solved once by minimax and the results are embedded in the
+// binary as tictactoe.csv, so a lookup takes constant time. The table is
+// also a ground-truth dataset: each row holds a position as board.Parse
+// reads it, its key, its value for the player to move and the optimal
+// moves, by cell name.
+package oracle
+
+//go:generate go run generate.go
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+//go:embed tictactoe.csv
+var table string
+
+// Entry is the solution of a position.
+type Entry struct {
+	Value   int          // Game-theoretic value for the player to move, 1 win 0 draw -1 loss
+	Optimal []env.Action // Moves that keep Value, in the order of State.Actions; none once the game is over
+}
+
+var (
+	loadOnce sync.Once
+	entries  map[int64]Entry // By board ID
+)
+
+// load parses the table. It panics if the table is malformed, which the
+// tests rule out.
+func load() {
+	rows, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("oracle: reading table: %v", err))
+	}
+
+	empty := board.NewBoard(1)
+	entries = make(map[int64]Entry, len(rows))
+	for _, row := range rows[1:] { // after the header
+		id, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("oracle: key of %s: %v", row[0], err))
+		}
+		e := Entry{}
+		switch row[2] {
+		case "win":
+			e.Value = 1
+		case "loss":
+			e.Value = -1
+		}
+		for _, cell := range strings.Fields(row[3]) {
+			a, err := empty.ParseAction(cell)
+			if err != nil {
+				panic(fmt.Sprintf("oracle: move of %s: %v", row[0], err))
+			}
+			e.Optimal = append(e.Optimal, a)
+		}
+		entries[id] = e
+	}
+}
+
+// position is a state of a board that has a board ID, board.Board or
+// board.Bitboard.
+type position interface {
+	Config() board.Config
+	ID() int64
+}
+
+// Lookup returns the solution of s, or false if s is not a position of
+// standard 3x3 tic-tac-toe.
+func Lookup(s env.State) (Entry, bool) {
+	p, ok := s.(position)
+	if !ok || p.Config() != board.TicTacToe {
+		return Entry{}, false
+	}
+	loadOnce.Do(load)
+	e, ok := entries[p.ID()]
+	return e, ok
+}
+
+// Len returns the number of positions in the table.
+func Len() int {
+	loadOnce.Do(load)
+	return len(entries)
+}
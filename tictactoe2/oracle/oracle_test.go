@@ -0,0 +1,78 @@
+package oracle_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/oracle"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/statespace"
+)
+
+// Test that the table agrees with minimax on every position, so it is not
+// stale; run go generate to rebuild it.
+func TestTable(t *testing.T) {
+	if oracle.Len() != 2*5478 {
+		t.Errorf("table has %d positions, want %d", oracle.Len(), 2*5478)
+	}
+	solvers := [3]*player.MinimaxPlayer{nil, player.NewMinimaxPlayer(1), player.NewMinimaxPlayer(2)}
+	for start := 1; start <= 2; start++ {
+		for _, pos := range statespace.Enumerate(board.NewBoard(start)).Positions {
+			e, ok := oracle.Lookup(pos.State)
+			if !ok {
+				t.Fatalf("%v is not in the table", pos.State)
+			}
+			if pos.Winner() != 0 {
+				if len(e.Optimal) != 0 {
+					t.Errorf("%v is over but has optimal moves %v", pos.State, e.Optimal)
+				}
+				continue
+			}
+			best, value := solvers[pos.Player()].OptimalMoves(pos.State)
+			if e.Value != value || !reflect.DeepEqual(e.Optimal, best) {
+				t.Errorf("%v: table has %d %v, minimax %d %v", pos.State, e.Value, e.Optimal, value, best)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	b, err := board.Parse(board.TicTacToe, "X.O/.X./... o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := oracle.Lookup(b)
+	if c1 := b.ActionAt(2, 2); !ok || e.Value != 0 || !reflect.DeepEqual(e.Optimal, []env.Action{c1}) {
+		t.Errorf("Lookup(%v) = %+v, %v, want a draw by blocking at c1", b, e, ok)
+	}
+
+	bb := board.NewBitboard(1)
+	bb.MakeMove(1, 1, 1)
+	if e, ok := oracle.Lookup(bb); !ok || e.Value != 0 {
+		t.Errorf("Lookup on a bitboard = %+v, %v, want a draw", e, ok)
+	}
+
+	misere := board.TicTacToe
+	misere.Rules = board.Misere
+	if _, ok := oracle.Lookup(board.NewBoardConfig(misere, 1)); ok {
+		t.Error("Lookup found a misere position")
+	}
+}
+
+func TestOraclePlayer(t *testing.T) {
+	for start := 1; start <= 2; start++ {
+		for _, pos := range statespace.Enumerate(board.NewBoard(start)).ToMove(1).Positions {
+			op, mp := player.NewOraclePlayer(1), player.NewMinimaxPlayer(1)
+			for _, a := range pos.Actions() {
+				if got, want := op.MoveValue(pos.State, a), mp.MoveValue(pos.State, a); got != want {
+					t.Fatalf("%v: MoveValue(%v) = %d, minimax %d", pos.State, a, got, want)
+				}
+			}
+			if got, want := op.MakeMove(pos.State), mp.MakeMove(pos.State); got != want {
+				t.Fatalf("%v: MakeMove = %v, minimax %v", pos.State, got, want)
+			}
+		}
+	}
+}
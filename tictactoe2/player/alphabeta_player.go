@@ -0,0 +1,91 @@
+package player
+
+import (
+	"math"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// AlphaBetaPlayer runs a depth-limited minimax search with alpha-beta
+// pruning. Positions at the depth limit are scored by env.Heuristic when the
+// state implements it, and as 0 otherwise.
+type AlphaBetaPlayer struct {
+	player int
+	depth  int // Moves to look ahead
+}
+
+func NewAlphaBetaPlayer(player int, depth int) *AlphaBetaPlayer {
+	return &AlphaBetaPlayer{
+		player: player,
+		depth:  depth,
+	}
+}
+
+func (p *AlphaBetaPlayer) GetPlayer() int {
+	return p.player
+}
+
+func (p *AlphaBetaPlayer) SetPlayer(player int) {
+	p.player = player
+}
+
+func (p *AlphaBetaPlayer) MakeMove(state env.State) env.Action {
+	actions := state.Actions()
+	best := actions[0]
+	alpha := math.Inf(-1)
+	for _, action := range actions {
+		next, _ := state.Apply(action)
+		eval := p.search(next, p.depth-1, alpha, math.Inf(1))
+		if eval > alpha {
+			alpha = eval
+			best = action
+		}
+	}
+	return best
+}
+
+// search returns the value of state for this player, looking depth moves ahead.
+func (p *AlphaBetaPlayer) search(state env.State, depth int, alpha, beta float64) float64 {
+	// finished games score beyond any heuristic, and sooner is better
+	switch win := state.Winner(); win {
+	case p.player:
+		return 2 + float64(depth)/100
+	case 3 - p.player:
+		return -2 - float64(depth)/100
+	case 3:
+		return 0
+	}
+
+	if depth <= 0 {
+		if h, ok := state.(env.Heuristic); ok {
+			return h.Evaluate(p.player)
+		}
+		return 0
+	}
+
+	if state.Player() == p.player {
+		for _, action := range state.Actions() {
+			next, _ := state.Apply(action)
+			alpha = math.Max(alpha, p.search(next, depth-1, alpha, beta))
+			if alpha >= beta {
+				break
+			}
+		}
+		return alpha
+	}
+
+	for _, action := range state.Actions() {
+		next, _ := state.Apply(action)
+		beta = math.Min(beta, p.search(next, depth-1, alpha, beta))
+		if alpha >= beta {
+			break
+		}
+	}
+	return beta
+}
+
+func (p *AlphaBetaPlayer) Win() {
+}
+
+func (p *AlphaBetaPlayer) Lose() {
+}
@@ -3,48 +3,121 @@ package player
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
 	"strings"
 
-	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
 )
 
+// Requests a human player can make instead of a move. They are negative,
+// so no state accepts them as actions, and game.Game acts on them.
+const (
+	Undo   env.Action = -1 - iota // Take back the player's last move
+	Resign                        // Concede the game
+	Quit                          // Abandon the game
+)
+
+// stdin is shared by every human player reading the terminal, so input
+// buffered for one is not lost to another.
+var stdin = bufio.NewReader(os.Stdin)
+
+// HumanPlayer asks for moves on the terminal. Besides moves it accepts the
+// commands undo, hint, resign and quit; the end of the input quits.
 type HumanPlayer struct {
 	player int // 1 or 2
+	in     *bufio.Reader
+	out    io.Writer
+	hint   Player // Suggests moves for hint
+	tui    bool   // Whether to pick cells with a cursor
+	cursor [2]int // Cell under the cursor
 }
 
 func NewHumanPlayer(player int) *HumanPlayer {
+	return NewHumanPlayerIO(player, stdin, os.Stdout)
+}
+
+// NewHumanPlayerIO creates a human player that reads moves from in and
+// writes prompts to out.
+func NewHumanPlayerIO(player int, in *bufio.Reader, out io.Writer) *HumanPlayer {
 	return &HumanPlayer{
 		player: player,
+		in:     in,
+		out:    out,
+		hint:   NewOraclePlayer(player),
 	}
 }
 
-// MakeMove asks the user for coordinates and validates the move
-func (hp *HumanPlayer) MakeMove(b *board.Board) (int, int, int) {
-	reader := bufio.NewReader(os.Stdin)
+// SetHint sets the player asked for hints, OraclePlayer by default, which
+// is only feasible for small games.
+func (hp *HumanPlayer) SetHint(p Player) {
+	hp.hint = p
+}
+
+// SetTUI turns on picking cells with the cursor keys on boards, when the
+// input is a terminal.
+func (hp *HumanPlayer) SetTUI(on bool) {
+	hp.tui = on
+}
+
+// MakeMove asks the user for a move and validates it. It returns Undo,
+// Resign or Quit if the user asks for them.
+func (hp *HumanPlayer) MakeMove(state env.State) env.Action {
+	if g, ok := state.(Grid); ok && hp.tui {
+		if action, ok := hp.cursorMove(state, g); ok {
+			return action
+		}
+	}
+
+	example := state.FormatAction(state.Actions()[0])
 	for {
-		fmt.Printf("Player %d, enter your move as \"x y\": ", hp.player)
-		text, _ := reader.ReadString('\n')
+		fmt.Fprintf(hp.out, "Player %d, enter your move (e.g. \"%s\") or undo, hint, resign, quit: ", hp.player, example)
+		text, err := hp.in.ReadString('\n')
+		if err != nil && text == "" {
+			fmt.Fprintln(hp.out)
+			return Quit
+		}
 		text = strings.TrimSpace(text)
-		parts := strings.Split(text, " ")
-		if len(parts) != 2 {
-			fmt.Println("Please enter two numbers separated by a space.")
+		switch strings.ToLower(text) {
+		case "undo":
+			return Undo
+		case "resign":
+			return Resign
+		case "quit", "exit":
+			return Quit
+		case "hint":
+			fmt.Fprintln(hp.out, hp.suggest(state))
 			continue
 		}
-		x, err1 := strconv.Atoi(parts[0])
-		y, err2 := strconv.Atoi(parts[1])
-		if err1 != nil || err2 != nil || x < 0 || x > 2 || y < 0 || y > 2 {
-			fmt.Println("Invalid coordinates. Enter x and y between 0 and 2.")
+
+		action, err := state.ParseAction(text)
+		if err != nil {
+			fmt.Fprintf(hp.out, "Invalid move: %v. Try again.\n", err)
 			continue
 		}
-		_, ok := b.TryMove(b.Get(), x, y, hp.player)
-		if !ok {
-			fmt.Println("Invalid move. Cell already taken. Try again.")
+		if _, ok := state.Apply(action); !ok {
+			fmt.Fprintln(hp.out, "Invalid move. Try again.")
 			continue
 		}
-		return x, y, hp.player
+		return action
+	}
+}
+
+// suggest describes the hint player's choice of move in state.
+func (hp *HumanPlayer) suggest(state env.State) string {
+	hp.hint.SetPlayer(state.Player())
+	if solver, ok := hp.hint.(interface {
+		OptimalMoves(env.State) ([]env.Action, int)
+	}); ok {
+		best, value := solver.OptimalMoves(state)
+		moves := []string{}
+		for _, a := range best {
+			moves = append(moves, state.FormatAction(a))
+		}
+		outcome := map[int]string{1: "wins", 0: "draws", -1: "loses"}[value]
+		return fmt.Sprintf("Hint: %s (%s with perfect play)", strings.Join(moves, ", "), outcome)
 	}
+	return "Hint: " + state.FormatAction(hp.hint.MakeMove(state))
 }
 
 func (hp *HumanPlayer) Win() {
@@ -5,29 +5,34 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"os"
 	"strconv"
 
-	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/policy"
 )
 
 type LearnerPlayer struct {
-	player       int               // 1 or 2
-	model        map[int64]float64 // Model to store Q-values
-	epsilon      float64           // Exploration rate
-	history      []int64           // History of moves for training
-	learningRate float64           // Learning rate for Q-learning
-	mode         string            // Mode of the player (e.g., "learner", "explorer")
+	player       int                // 1 or 2
+	model        map[string]float64 // Model to store afterstate values, by State.Key
+	epsilon      policy.Schedule    // Exploration rate
+	history      []string           // History of afterstates for training
+	learningRate policy.Schedule    // Learning rate for Q-learning
+	games        int                // Games learned from, the step of the schedules
+	mode         string             // Mode of the player ("learner", "player" or "frozen")
+	tdErrorSum   float64            // Sum of absolute TD errors since the last TakeTDError
+	tdUpdates    int                // Number of updates in tdErrorSum
 }
 
 func NewLearnerPlayer(player int, epsilon float64, learningRate float64, mode string) *LearnerPlayer {
 	return &LearnerPlayer{
 		player:       player,
-		epsilon:      epsilon,
-		model:        make(map[int64]float64),
-		history:      []int64{},
-		learningRate: learningRate,
+		epsilon:      policy.Constant(epsilon),
+		model:        make(map[string]float64),
+		history:      []string{},
+		learningRate: policy.Constant(learningRate),
 		mode:         mode,
 	}
 }
@@ -40,9 +45,78 @@ func (lp *LearnerPlayer) SetPlayer(player int) {
 	lp.player = player
 }
 
-func (lp *LearnerPlayer) LoadModel(path string) error {
-	m := map[string]float64{}
+// SetSchedules replaces the constant exploration and learning rates with
+// schedules over the number of games the learner has learned from.
+func (lp *LearnerPlayer) SetSchedules(epsilon, learningRate policy.Schedule) {
+	lp.epsilon = epsilon
+	lp.learningRate = learningRate
+}
+
+func (lp *LearnerPlayer) GetEpsilon() float64 {
+	return lp.epsilon.At(lp.games)
+}
+
+func (lp *LearnerPlayer) GetLearningRate() float64 {
+	return lp.learningRate.At(lp.games)
+}
+
+// ModelSize returns the number of states in the model.
+func (lp *LearnerPlayer) ModelSize() int {
+	return len(lp.model)
+}
+
+// HasValue reports whether the model holds a value for the state with key,
+// that is whether the learner has learned from reaching it.
+func (lp *LearnerPlayer) HasValue(key string) bool {
+	_, ok := lp.model[key]
+	return ok
+}
+
+// TakeTDError returns the mean absolute TD error of the updates made since
+// the previous call and resets it.
+func (lp *LearnerPlayer) TakeTDError() float64 {
+	mean := 0.0
+	if lp.tdUpdates > 0 {
+		mean = lp.tdErrorSum / float64(lp.tdUpdates)
+	}
+	lp.tdErrorSum = 0
+	lp.tdUpdates = 0
+	return mean
+}
+
+// update moves the value of id towards target and records the TD error.
+func (lp *LearnerPlayer) update(id string, target float64) {
+	tdError := target - lp.model[id]
+	lp.tdErrorSum += math.Abs(tdError)
+	lp.tdUpdates++
+
+	lp.model[id] += lp.GetLearningRate() * tdError // Update Q-value
+
+	if lp.model[id] < 0 {
+		lp.model[id] = 0 // Ensure Q-value does not go below 0
+	}
 
+	if lp.model[id] > 1 {
+		lp.model[id] = 1 // Ensure Q-value does not exceed 1
+	}
+}
+
+// ModelVersion is the version of the model files SaveModel writes, an
+// object holding the version and the values keyed by State.Key, whose last
+// bit on boards is the player to move after the move valued.
+//
+// Version 1 files are a bare object of values keyed by board ID with the
+// last bit of the player who moved. LoadModel migrates them by flipping that
+// bit, so models trained before the version was added keep their values.
+const ModelVersion = 2
+
+// modelFile is the format of model files from version 2.
+type modelFile struct {
+	Version int                `json:"version"`
+	Values  map[string]float64 `json:"values"`
+}
+
+func (lp *LearnerPlayer) LoadModel(path string) error {
 	// Load the model from the specified path
 	fp, err := os.Open(path)
 	if err != nil {
@@ -55,31 +129,48 @@ func (lp *LearnerPlayer) LoadModel(path string) error {
 		return err
 	}
 
-	if err := json.Unmarshal(data, &m); err != nil {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
 		return err
 	}
+	if _, ok := fields["version"]; !ok {
+		return lp.loadVersion1(data)
+	}
 
+	file := modelFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	if file.Version != ModelVersion {
+		return fmt.Errorf("model version %d is not supported, want %d", file.Version, ModelVersion)
+	}
+	for id, value := range file.Values {
+		lp.model[id] = value
+	}
+	return nil
+}
+
+// loadVersion1 loads a version 1 model, moving the player bit of each key
+// from the player who moved to the player to move.
+func (lp *LearnerPlayer) loadVersion1(data []byte) error {
+	m := map[string]float64{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
 	for k, v := range m {
-		id, err := strconv.Atoi(k)
+		id, err := strconv.ParseInt(k, 10, 64)
 		if err != nil {
-			return errors.New("invalid key in model: " + k)
+			return errors.New("invalid key in version 1 model: " + k)
 		}
-
-		lp.model[int64(id)] = v
+		lp.model[strconv.FormatInt(id^1, 10)] = v
 	}
 	return nil
 }
 
-// SaveModel saves the model to the specified path
+// SaveModel saves the model to the specified path, in the format of
+// ModelVersion.
 func (lp *LearnerPlayer) SaveModel(path string) error {
-	m := make(map[string]float64)
-
-	// Convert the model to a map with string keys
-	for k, v := range lp.model {
-		m[strconv.FormatInt(k, 10)] = v
-	}
-
-	data, err := json.MarshalIndent(m, "", "  ")
+	data, err := json.MarshalIndent(modelFile{Version: ModelVersion, Values: lp.model}, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -98,61 +189,99 @@ func (lp *LearnerPlayer) SaveModel(path string) error {
 }
 
 // AddHistoryEntry adds a new entry to the player's history
-func (lp *LearnerPlayer) AddHistoryEntry(b *board.Board, x, y int) {
-	// Create a new history entry with the current board state
-	newBoard, _ := b.TryMove(b.Get(), x, y, lp.player)
-
-	id := b.CalcID(newBoard, b.GetStart(), lp.player)
-	lp.history = append(lp.history, id)
+func (lp *LearnerPlayer) AddHistoryEntry(state env.State, action env.Action) {
+	// Create a new history entry with the state after the move
+	next, _ := state.Apply(action)
+	lp.history = append(lp.history, next.Key())
 }
 
 // MakeMove is a placeholder for the learner player logic
-func (lp *LearnerPlayer) MakeMove(b *board.Board) (int, int, int) {
-	maxValue := float64(-1) // Initialize to a very low value
-	maxActions := []board.Action{}
-
-	actions := b.GetPossibleMoves()
+func (lp *LearnerPlayer) MakeMove(state env.State) env.Action {
 	if lp.mode == "learner" {
-		if randFloat := rand.Float64(); randFloat < lp.epsilon {
+		if policy.Explore(lp.GetEpsilon()) {
 			// Explore: choose a random action
+			actions := state.Actions()
 			action := actions[rand.Intn(len(actions))]
-			lp.AddHistoryEntry(b, action.X, action.Y)
-			return action.X, action.Y, lp.player
+			lp.AddHistoryEntry(state, action)
+			return action
 		}
 	}
 
-	startBoard := b.Get()
-	for _, action := range actions {
-		newBoard, _ := b.TryMove(startBoard, action.X, action.Y, lp.player)
-		id := b.CalcID(newBoard, b.GetStart(), lp.player)
-		if _, exists := lp.model[id]; !exists {
+	maxActions := lp.GreedyMoves(state)
+	action := maxActions[rand.Intn(len(maxActions))]
+	if lp.mode != "frozen" {
+		lp.AddHistoryEntry(state, action)
+	}
+
+	return action
+}
+
+// GreedyMoves returns every action that ties for the highest value in the model.
+func (lp *LearnerPlayer) GreedyMoves(state env.State) []env.Action {
+	actions := state.Actions()
+	maxActions := []env.Action{}
+	for _, i := range policy.Greedy(lp.ActionValues(state)) {
+		maxActions = append(maxActions, actions[i])
+	}
+	return maxActions
+}
+
+// ActionValues returns the model's value of the state after each action in
+// state.Actions(), in the same order.
+func (lp *LearnerPlayer) ActionValues(state env.State) []float64 {
+	values := []float64{}
+
+	for _, action := range state.Actions() {
+		next, _ := state.Apply(action)
+		id := next.Key()
+		value, exists := lp.model[id]
+		if !exists {
 			// if the board state is a winning state, assign value 1.0,
 			// if it's a losing state, assign value 0,
 			// otherwise initialize to 0.5
-			if b.CalcWin(newBoard) == lp.player {
-				lp.model[id] = 1 // Initialize Q-value if not present
+			if next.Winner() == lp.player {
+				value = 1
 			} else {
-				lp.model[id] = 0.5 // Initialize Q-value if not present
+				value = 0.5
+			}
+			if lp.mode != "frozen" {
+				lp.model[id] = value // Initialize Q-value if not present
 			}
 		}
 
-		if lp.mode != "learner" {
-			fmt.Println("Action:", action.X, action.Y, "ID:", id, "Value:", lp.model[id])
+		if lp.mode == "player" {
+			fmt.Println("Action:", state.FormatAction(action), "ID:", id, "Value:", value)
 		}
 
-		value := lp.model[id]
-		if value > maxValue {
-			maxValue = value
-			maxActions = []board.Action{action}
-		} else if value >= maxValue-0.0001 && value <= maxValue+0.0001 {
-			maxActions = append(maxActions, action)
-		}
+		values = append(values, value)
 	}
 
-	action := maxActions[rand.Intn(len(maxActions))]
-	lp.AddHistoryEntry(b, action.X, action.Y)
+	return values
+}
 
-	return action.X, action.Y, lp.player
+// Frozen returns a copy of the learner that shares its model but always
+// plays greedily and never updates the model. It is used for evaluation.
+func (lp *LearnerPlayer) Frozen() *LearnerPlayer {
+	return &LearnerPlayer{
+		player:       lp.player,
+		epsilon:      policy.Constant(0),
+		model:        lp.model,
+		history:      []string{},
+		learningRate: lp.learningRate,
+		games:        lp.games,
+		mode:         "frozen",
+	}
+}
+
+// Snapshot returns a frozen copy of the learner with its own copy of the
+// model, so it keeps playing as the learner did when it was taken.
+func (lp *LearnerPlayer) Snapshot() *LearnerPlayer {
+	snapshot := lp.Frozen()
+	snapshot.model = make(map[string]float64, len(lp.model))
+	for id, value := range lp.model {
+		snapshot.model[id] = value
+	}
+	return snapshot
 }
 
 func (lp *LearnerPlayer) Win() {
@@ -165,20 +294,11 @@ func (lp *LearnerPlayer) Win() {
 				lp.model[id] = 0.5 // Increase Q-value for winning moves
 			}
 
-			// old := lp.model[id]
-			lp.model[id] += lp.learningRate * (lp.model[nextID] - lp.model[id]) // Update Q-value
-			// fmt.Println("Updating ID:", id, "Old:", old, "Value:", lp.model[id])
-
-			if lp.model[id] < 0 {
-				lp.model[id] = 0 // Ensure Q-value does not go below 0
-			}
-
-			if lp.model[id] > 1 {
-				lp.model[id] = 1 // Ensure Q-value does not exceed 1
-			}
+			lp.update(id, lp.model[nextID])
 		}
+		lp.games++
 	}
-	lp.history = []int64{} // Clear history after updating
+	lp.history = []string{} // Clear history after updating
 }
 
 func (lp *LearnerPlayer) Lose() {
@@ -198,17 +318,9 @@ func (lp *LearnerPlayer) Lose() {
 				lp.model[id] = 0.5 // Decrease Q-value for losing moves
 			}
 
-			// old := lp.model[id]
-			lp.model[id] += lp.learningRate * (nextValue - lp.model[id]) // Update Q-value
-			//fmt.Println("Updating ID:", id, "Old:", old, "Value:", lp.model[id])
-			if lp.model[id] < 0 {
-				lp.model[id] = 0 // Ensure Q-value does not go below 0
-			}
-
-			if lp.model[id] > 1 {
-				lp.model[id] = 1 // Ensure Q-value does not exceed 1
-			}
+			lp.update(id, nextValue)
 		}
+		lp.games++
 	}
-	lp.history = []int64{} // Clear history after updating
+	lp.history = []string{} // Clear history after updating
 }
@@ -0,0 +1,141 @@
+package player
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// MCTSPlayer plays by Monte Carlo tree search (UCT) with random playouts.
+type MCTSPlayer struct {
+	player      int
+	iterations  int     // Playouts per move
+	exploration float64 // UCT exploration constant
+}
+
+func NewMCTSPlayer(player int, iterations int) *MCTSPlayer {
+	return &MCTSPlayer{
+		player:      player,
+		iterations:  iterations,
+		exploration: math.Sqrt2,
+	}
+}
+
+func (p *MCTSPlayer) GetPlayer() int {
+	return p.player
+}
+
+func (p *MCTSPlayer) SetPlayer(player int) {
+	p.player = player
+}
+
+type mctsNode struct {
+	state    env.State
+	action   env.Action // Action that led here from parent
+	parent   *mctsNode
+	children []*mctsNode
+	untried  []env.Action
+	visits   float64
+	wins     float64 // Reward for the player who moved into this node
+}
+
+func newMCTSNode(state env.State, action env.Action, parent *mctsNode) *mctsNode {
+	return &mctsNode{
+		state:   state,
+		action:  action,
+		parent:  parent,
+		untried: state.Actions(),
+	}
+}
+
+func (p *MCTSPlayer) MakeMove(state env.State) env.Action {
+	root := newMCTSNode(state, 0, nil)
+
+	for i := 0; i < p.iterations; i++ {
+		node := root
+
+		// Selection
+		for len(node.untried) == 0 && len(node.children) > 0 {
+			node = p.selectChild(node)
+		}
+
+		// Expansion
+		if len(node.untried) > 0 {
+			idx := rand.Intn(len(node.untried))
+			action := node.untried[idx]
+			node.untried = append(node.untried[:idx], node.untried[idx+1:]...)
+			next, _ := node.state.Apply(action)
+			child := newMCTSNode(next, action, node)
+			node.children = append(node.children, child)
+			node = child
+		}
+
+		// Simulation
+		winner := playout(node.state)
+
+		// Backpropagation
+		for n := node; n.parent != nil; n = n.parent {
+			n.visits++
+			mover := n.parent.state.Player()
+			if winner == mover {
+				n.wins++
+			} else if winner == 3 {
+				n.wins += 0.5
+			}
+		}
+		root.visits++
+	}
+
+	// play the most visited move, or any move if there were no playouts
+	if len(root.children) == 0 {
+		actions := state.Actions()
+		return actions[rand.Intn(len(actions))]
+	}
+	best := root.children[0]
+	for _, child := range root.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.action
+}
+
+// selectChild returns the child with the highest UCT score.
+func (p *MCTSPlayer) selectChild(node *mctsNode) *mctsNode {
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	logVisits := math.Log(node.visits)
+	for _, child := range node.children {
+		score := child.wins/child.visits + p.exploration*math.Sqrt(logVisits/child.visits)
+		if score > bestScore {
+			bestScore = score
+			best = child
+		}
+	}
+	return best
+}
+
+// playout plays random moves from state until the game ends and returns the winner.
+// Mutable states are played on a single copy.
+func playout(state env.State) int {
+	if m, ok := state.(env.Mutable); ok {
+		m = m.Copy()
+		for m.Winner() == 0 {
+			actions := m.Actions()
+			m.Play(actions[rand.Intn(len(actions))])
+		}
+		return m.Winner()
+	}
+	for state.Winner() == 0 {
+		actions := state.Actions()
+		state, _ = state.Apply(actions[rand.Intn(len(actions))])
+	}
+	return state.Winner()
+}
+
+func (p *MCTSPlayer) Win() {
+}
+
+func (p *MCTSPlayer) Lose() {
+}
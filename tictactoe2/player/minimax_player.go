@@ -1,16 +1,23 @@
 package player
 
 import (
-	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"fmt"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
 )
 
+// MinimaxPlayer searches the full game tree. It is only feasible for small
+// games such as 3x3 tic-tac-toe.
 type MinimaxPlayer struct {
 	player int
+	cache  map[string]int // Values of states already searched, by State.Key
+	game   string         // Game of the cached states, see gameOf
 }
 
 func NewMinimaxPlayer(player int) *MinimaxPlayer {
 	return &MinimaxPlayer{
 		player: player,
+		cache:  make(map[string]int),
 	}
 }
 
@@ -19,11 +26,44 @@ func (p *MinimaxPlayer) GetPlayer() int {
 }
 
 func (p *MinimaxPlayer) SetPlayer(player int) {
+	if player != p.player {
+		// cached values are from the old player's point of view
+		p.cache = make(map[string]int)
+	}
 	p.player = player
 }
 
-func (p *MinimaxPlayer) minimax(board *board.Board, brdArray [9]int, maximizing bool) int {
-	win := board.CalcWin(brdArray)
+// gameOf identifies the game of state, its type and, for env.Configured
+// states, its configuration. Keys are only unique within a game.
+func gameOf(state env.State) string {
+	game := fmt.Sprintf("%T", state)
+	if c, ok := state.(env.Configured); ok {
+		game += " " + c.ConfigKey()
+	}
+	return game
+}
+
+// use clears the cache if state is of another game than the cached states.
+func (p *MinimaxPlayer) use(state env.State) {
+	if game := gameOf(state); game != p.game {
+		p.cache = make(map[string]int)
+		p.game = game
+	}
+}
+
+// minimax returns the game-theoretic value of state for this player.
+func (p *MinimaxPlayer) minimax(state env.State) int {
+	key := state.Key()
+	if eval, ok := p.cache[key]; ok {
+		return eval
+	}
+	eval := p.search(state)
+	p.cache[key] = eval
+	return eval
+}
+
+func (p *MinimaxPlayer) search(state env.State) int {
+	win := state.Winner()
 	if win == p.player {
 		return 1
 	} else if win == 3-p.player {
@@ -32,53 +72,87 @@ func (p *MinimaxPlayer) minimax(board *board.Board, brdArray [9]int, maximizing
 		return 0
 	}
 
-	if maximizing {
+	if state.Player() == p.player {
 		maxEval := -2
 		// more moves available
-		actions := board.CalcPossibleMoves(brdArray)
-		for _, action := range actions {
-			newBoard := brdArray
-			newBoard[action.X+3*action.Y] = p.player
-			eval := p.minimax(board, newBoard, false)
-			if eval > maxEval {
+		eachChild(state, func(next env.State) {
+			if eval := p.minimax(next); eval > maxEval {
 				maxEval = eval
 			}
-		}
+		})
 
 		return maxEval
 	} else {
 		minEval := 2
 		// more moves available
-		actions := board.CalcPossibleMoves(brdArray)
-		for _, action := range actions {
-			newBoard := brdArray
-			newBoard[action.X+3*action.Y] = 3 - p.player
-			eval := p.minimax(board, newBoard, true)
-			if eval < minEval {
+		eachChild(state, func(next env.State) {
+			if eval := p.minimax(next); eval < minEval {
 				minEval = eval
 			}
-		}
+		})
 
 		return minEval
 	}
 }
 
-func (p *MinimaxPlayer) MakeMove(board *board.Board) (int, int, int) {
-	brdArray := board.Get()
-	actions := board.GetPossibleMoves()
+// eachChild calls f with the state after each legal action of state. A
+// mutable state is played in place and restored after each call, so f must
+// not keep its argument and state must not be shared while eachChild runs.
+func eachChild(state env.State, f func(next env.State)) {
+	m, mutable := state.(env.Mutable)
+	for _, action := range state.Actions() {
+		if mutable {
+			m.Play(action)
+			f(m)
+			m.UndoMove()
+			continue
+		}
+		next, _ := state.Apply(action)
+		f(next)
+	}
+}
+
+func (p *MinimaxPlayer) MakeMove(state env.State) env.Action {
+	actions := state.Actions()
 	maxEval := -2
 	maxIdx := 0
 	for idx, action := range actions {
-		newBoard := brdArray
-		newBoard[action.X+3*action.Y] = action.Player
-		eval := p.minimax(board, newBoard, false)
+		eval := p.MoveValue(state, action)
 		if eval > maxEval {
 			maxEval = eval
 			maxIdx = idx
 		}
 	}
 
-	return actions[maxIdx].X, actions[maxIdx].Y, p.player
+	return actions[maxIdx]
+}
+
+// MoveValue returns the game-theoretic value for this player (1 win, 0 draw,
+// -1 loss) of playing action in state.
+func (p *MinimaxPlayer) MoveValue(state env.State, action env.Action) int {
+	p.use(state)
+	// next is a new state, so the search may play in it
+	next, _ := state.Apply(action)
+	return p.minimax(next)
+}
+
+// OptimalMoves returns every move that keeps the game-theoretic value of the
+// state for this player, along with that value. The state must have this
+// player to move.
+func (p *MinimaxPlayer) OptimalMoves(state env.State) ([]env.Action, int) {
+	maxEval := -2
+	best := []env.Action{}
+	for _, action := range state.Actions() {
+		eval := p.MoveValue(state, action)
+		if eval > maxEval {
+			maxEval = eval
+			best = []env.Action{action}
+		} else if eval == maxEval {
+			best = append(best, action)
+		}
+	}
+
+	return best, maxEval
 }
 
 func (p *MinimaxPlayer) Win() {
@@ -0,0 +1,68 @@
+package player
+
+import (
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/oracle"
+)
+
+// OraclePlayer plays perfectly by looking positions up in the table of
+// package oracle, in constant time. States the table does not hold, such as
+// those of other boards or rules, are searched by a MinimaxPlayer, so it
+// can stand in for one anywhere.
+type OraclePlayer struct {
+	player   int
+	fallback *MinimaxPlayer
+}
+
+func NewOraclePlayer(player int) *OraclePlayer {
+	return &OraclePlayer{
+		player:   player,
+		fallback: NewMinimaxPlayer(player),
+	}
+}
+
+func (p *OraclePlayer) GetPlayer() int {
+	return p.player
+}
+
+func (p *OraclePlayer) SetPlayer(player int) {
+	p.player = player
+	p.fallback.SetPlayer(player)
+}
+
+// MakeMove plays the move MinimaxPlayer would, the first optimal one.
+func (p *OraclePlayer) MakeMove(state env.State) env.Action {
+	if e, ok := oracle.Lookup(state); ok && len(e.Optimal) > 0 {
+		return e.Optimal[0]
+	}
+	return p.fallback.MakeMove(state)
+}
+
+// MoveValue returns the game-theoretic value for this player (1 win, 0 draw,
+// -1 loss) of playing action in state.
+func (p *OraclePlayer) MoveValue(state env.State, action env.Action) int {
+	next, _ := state.Apply(action)
+	if e, ok := oracle.Lookup(next); ok {
+		if next.Player() != p.player {
+			return -e.Value
+		}
+		return e.Value
+	}
+	return p.fallback.MoveValue(state, action)
+}
+
+// OptimalMoves returns every move that keeps the game-theoretic value of the
+// state for this player, along with that value. The state must have this
+// player to move.
+func (p *OraclePlayer) OptimalMoves(state env.State) ([]env.Action, int) {
+	if e, ok := oracle.Lookup(state); ok && state.Winner() == 0 {
+		return append([]env.Action{}, e.Optimal...), e.Value
+	}
+	return p.fallback.OptimalMoves(state)
+}
+
+func (p *OraclePlayer) Win() {
+}
+
+func (p *OraclePlayer) Lose() {
+}
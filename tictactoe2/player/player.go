@@ -1,10 +1,10 @@
 package player
 
-import "github.com/param108/reinforcement-learning/tictactoe2/board"
+import "github.com/param108/reinforcement-learning/tictactoe2/env"
 
 type Player interface {
-	// MakeMove returns x, y, player
-	MakeMove(board *board.Board) (int, int, int)
+	// MakeMove returns the action to play in state, which has this player to move
+	MakeMove(state env.State) env.Action
 	Win()
 	Lose()
 	GetPlayer() int
@@ -0,0 +1,178 @@
+package player
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// position plays moves (x, y pairs, X first) on an empty board.
+func position(t *testing.T, moves ...[2]int) *board.Board {
+	b := board.NewBoard(1)
+	for _, m := range moves {
+		if !b.MakeMove(m[0], m[1], b.NextPlayer()) {
+			t.Fatalf("move %v rejected", m)
+		}
+	}
+	return b
+}
+
+// Test that minimax values the empty board as a draw and finds forced wins
+func TestMinimaxOptimalMoves(t *testing.T) {
+	p := NewMinimaxPlayer(1)
+	if _, value := p.OptimalMoves(board.NewBoard(1)); value != 0 {
+		t.Errorf("empty board value = %d; want 0", value)
+	}
+
+	// X to move can win at (2, 0)
+	b := position(t, [2]int{0, 0}, [2]int{0, 1}, [2]int{1, 0}, [2]int{1, 1})
+	best, value := p.OptimalMoves(b)
+	if value != 1 {
+		t.Errorf("value = %d; want 1", value)
+	}
+	if len(best) != 1 || best[0] != b.ActionAt(2, 0) {
+		t.Errorf("best = %v; want [%v]", best, b.ActionAt(2, 0))
+	}
+}
+
+// Test that MCTS takes an immediate win and blocks an immediate loss
+func TestMCTSTactics(t *testing.T) {
+	tests := []struct {
+		moves [][2]int
+		want  [2]int
+	}{
+		{[][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}}, [2]int{2, 0}},         // X wins
+		{[][2]int{{0, 0}, {1, 1}, {2, 2}, {0, 2}}, [2]int{2, 0}},         // X blocks O's diagonal
+		{[][2]int{{1, 1}, {0, 0}, {2, 2}, {2, 0}, {1, 0}}, [2]int{1, 2}}, // O blocks X's column
+	}
+	for _, tt := range tests {
+		b := position(t, tt.moves...)
+		p := NewMCTSPlayer(b.NextPlayer(), 2000)
+		got := p.MakeMove(b)
+		if want := b.ActionAt(tt.want[0], tt.want[1]); got != want {
+			t.Errorf("%v: MakeMove = %s; want %s", tt.moves, b.FormatAction(got), b.FormatAction(want))
+		}
+	}
+}
+
+// Test that MCTS without playouts still plays a legal move
+func TestMCTSNoIterations(t *testing.T) {
+	b := position(t, [2]int{1, 1})
+	got := NewMCTSPlayer(b.NextPlayer(), 0).MakeMove(b)
+	if _, ok := b.Apply(got); !ok {
+		t.Errorf("MakeMove = %d; want a legal move", got)
+	}
+}
+
+// Test that a frozen learner neither explores nor changes the model
+func TestFrozenLearner(t *testing.T) {
+	lp := NewLearnerPlayer(1, 1, 0.1, "learner")
+	frozen := lp.Frozen()
+
+	var state env.State = board.NewBoard(1)
+	frozen.MakeMove(state)
+	frozen.Win()
+	if lp.ModelSize() != 0 {
+		t.Errorf("model has %d states after a frozen move; want 0", lp.ModelSize())
+	}
+}
+
+// Test the game-theoretic value of each 3x3 rule variant for X
+func TestMinimaxRuleVariants(t *testing.T) {
+	tests := []struct {
+		rules board.Rules
+		want  int
+	}{
+		{board.Standard, 0},
+		{board.Misere, 0},
+		{board.Wild, 1},
+		{board.Notakto, 1},
+		{board.NoDraws, -1},
+	}
+	for _, tt := range tests {
+		cfg := board.TicTacToe
+		cfg.Rules = tt.rules
+		if _, value := NewMinimaxPlayer(1).OptimalMoves(board.NewBoardConfig(cfg, 1)); value != tt.want {
+			t.Errorf("%v: value = %d; want %d", tt.rules, value, tt.want)
+		}
+	}
+
+	// the keys of the variants are the same, so a reused player must not
+	// answer from the values it cached for another
+	p := NewMinimaxPlayer(1)
+	for _, tt := range tests {
+		cfg := board.TicTacToe
+		cfg.Rules = tt.rules
+		if _, value := p.OptimalMoves(board.NewBoardConfig(cfg, 1)); value != tt.want {
+			t.Errorf("%v, reused player: value = %d; want %d", tt.rules, value, tt.want)
+		}
+	}
+}
+
+// Test the human player's commands, notation and end of input
+func TestHumanPlayer(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("hint\nzz\nc1\nundo\nresign\nquit\n9\n"))
+	out := &bytes.Buffer{}
+	hp := NewHumanPlayerIO(1, in, out)
+	b := position(t, [2]int{0, 0}, [2]int{0, 1}, [2]int{1, 0}, [2]int{1, 1})
+
+	if got := hp.MakeMove(b); got != b.ActionAt(2, 2) {
+		t.Errorf("c1 = %s", b.FormatAction(got))
+	}
+	if !strings.Contains(out.String(), "Hint: 2 0 (wins") || !strings.Contains(out.String(), "Invalid move") {
+		t.Errorf("output %q should have the hint and reject zz", out.String())
+	}
+	for _, want := range []env.Action{Undo, Resign, Quit} {
+		if got := hp.MakeMove(b); got != want {
+			t.Errorf("MakeMove = %d; want %d", got, want)
+		}
+	}
+	if got := hp.MakeMove(b); got != b.ActionAt(2, 0) {
+		t.Errorf("9 = %s", b.FormatAction(got))
+	}
+	if got := hp.MakeMove(b); got != Quit {
+		t.Errorf("MakeMove at the end of the input = %d; want Quit", got)
+	}
+}
+
+// Test that version 1 models are migrated and version 2 models round-trip
+func TestLoadModel(t *testing.T) {
+	dir := t.TempDir()
+	after := position(t, [2]int{1, 1}) // X's afterstate, O to move
+
+	// version 1 keyed X's afterstates with X's player bit
+	v1 := filepath.Join(dir, "v1.json")
+	os.WriteFile(v1, []byte(fmt.Sprintf(`{"%d": 0.75}`, after.ID()^1)), 0o644)
+	lp := NewLearnerPlayer(1, 0, 0, "frozen")
+	if err := lp.LoadModel(v1); err != nil {
+		t.Fatal(err)
+	}
+	if !lp.HasValue(after.Key()) {
+		t.Error("version 1 key was not migrated")
+	}
+
+	v2 := filepath.Join(dir, "v2.json")
+	if err := lp.SaveModel(v2); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewLearnerPlayer(1, 0, 0, "frozen")
+	if err := loaded.LoadModel(v2); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.HasValue(after.Key()) || loaded.ModelSize() != 1 {
+		t.Errorf("saved model did not load back, %d values", loaded.ModelSize())
+	}
+
+	v3 := filepath.Join(dir, "v3.json")
+	os.WriteFile(v3, []byte(`{"version": 3, "values": {}}`), 0o644)
+	if err := loaded.LoadModel(v3); err == nil {
+		t.Error("version 3 model loaded")
+	}
+}
@@ -0,0 +1,37 @@
+package player
+
+import (
+	"math/rand"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// RandomPlayer picks uniformly among the legal moves.
+type RandomPlayer struct {
+	player int
+}
+
+func NewRandomPlayer(player int) *RandomPlayer {
+	return &RandomPlayer{
+		player: player,
+	}
+}
+
+func (p *RandomPlayer) GetPlayer() int {
+	return p.player
+}
+
+func (p *RandomPlayer) SetPlayer(player int) {
+	p.player = player
+}
+
+func (p *RandomPlayer) MakeMove(state env.State) env.Action {
+	actions := state.Actions()
+	return actions[rand.Intn(len(actions))]
+}
+
+func (p *RandomPlayer) Win() {
+}
+
+func (p *RandomPlayer) Lose() {
+}
@@ -0,0 +1,148 @@
+package player
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Grid is a state played by marking a cell of a board, such as
+// board.Board, which HumanPlayer can draw with a cursor.
+type Grid interface {
+	Width() int
+	Height() int
+	Get() []int // Marks of the cells row by row, 0 - empty 1 - X 2 - O
+	ActionAt(x, y int) env.Action
+	ActionMark(x, y, mark int) env.Action
+}
+
+// rawMode puts the terminal on stdin in raw mode, so keys are read as they
+// are pressed, and returns the function that restores it. It fails if
+// stdin is not a terminal.
+func rawMode() (func(), error) {
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		return cmd.Output()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(string(saved))) }, nil
+}
+
+// cursorMove lets the user pick a cell of g with the arrow keys or h, j, k
+// and l, and place their mark with enter or space, or X or O where the
+// rules let them choose. u, ?, r and q undo, hint, resign and quit. It
+// returns false if the terminal cannot be put in raw mode.
+func (hp *HumanPlayer) cursorMove(state env.State, g Grid) (env.Action, bool) {
+	restore, err := rawMode()
+	if err != nil {
+		return 0, false
+	}
+	defer restore()
+
+	w, h := g.Width(), g.Height()
+	message := ""
+	for {
+		hp.draw(g, message)
+		message = ""
+
+		key, err := hp.in.ReadByte()
+		if err != nil {
+			return Quit, true
+		}
+		x, y := hp.cursor[0], hp.cursor[1]
+		mark := 0
+		switch key {
+		case 27: // escape sequences of the arrow keys
+			if next, _ := hp.in.ReadByte(); next != '[' {
+				continue
+			}
+			arrow, _ := hp.in.ReadByte()
+			switch arrow {
+			case 'A':
+				y--
+			case 'B':
+				y++
+			case 'C':
+				x++
+			case 'D':
+				x--
+			}
+		case 'h':
+			x--
+		case 'j':
+			y++
+		case 'k':
+			y--
+		case 'l':
+			x++
+		case '\r', '\n', ' ':
+			mark = -1
+		case 'x', 'X':
+			mark = 1
+		case 'o', 'O':
+			mark = 2
+		case 'u':
+			return Undo, true
+		case 'r':
+			return Resign, true
+		case 'q', 3, 4: // q, ctrl-C, ctrl-D
+			return Quit, true
+		case '?':
+			message = hp.suggest(state)
+			continue
+		}
+		hp.cursor = [2]int{(x + w) % w, (y + h) % h}
+
+		if mark != 0 {
+			action := g.ActionAt(hp.cursor[0], hp.cursor[1])
+			if mark > 0 {
+				action = g.ActionMark(hp.cursor[0], hp.cursor[1], mark)
+			}
+			if _, ok := state.Apply(action); ok {
+				fmt.Fprint(hp.out, "\r\n")
+				return action, true
+			}
+			message = "You cannot play there."
+		}
+	}
+}
+
+// draw clears the screen and draws g with the cursor's cell highlighted.
+// Lines end in \r\n because the terminal is in raw mode.
+func (hp *HumanPlayer) draw(g Grid, message string) {
+	w, h := g.Width(), g.Height()
+	cells := g.Get()
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			mark := []string{" . ", " X ", " O "}[cells[y*w+x]]
+			if hp.cursor == [2]int{x, y} {
+				mark = "\x1b[7m" + mark + "\x1b[0m"
+			}
+			sb.WriteString(mark)
+			if x < w-1 {
+				sb.WriteString("|")
+			}
+		}
+		sb.WriteString("\r\n")
+		if y < h-1 {
+			sb.WriteString(strings.Repeat("-", 4*w-1) + "\r\n")
+		}
+	}
+	fmt.Fprintf(&sb, "\r\nPlayer %d: move with the arrow keys, enter to play, u undo, ? hint, r resign, q quit\r\n", hp.player)
+	if message != "" {
+		sb.WriteString(message + "\r\n")
+	}
+	fmt.Fprint(hp.out, sb.String())
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/connect4"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/nim"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/remote"
+	"github.com/param108/reinforcement-learning/tictactoe2/ultimate"
+)
+
+// gameOptions holds the flags that choose a game and an AI player for it.
+type gameOptions struct {
+	game        *string
+	ai          *string
+	model       *string
+	depth       *int
+	iterations  *int
+	heaps       *string
+	take        *string
+	engine      *string
+	boardConfig func() board.Config
+}
+
+// gameFlags registers the game and AI flags on fs.
+func gameFlags(fs *flag.FlagSet) *gameOptions {
+	return &gameOptions{
+		game:        fs.String("game", "tictactoe", "game to play: tictactoe (with -width, -height, -k), connect4, ultimate, nim or subtraction (with -heaps, -take)"),
+		ai:          fs.String("ai", "", "AI player: learner, minimax, oracle, alphabeta, mcts, random, optimal for nim or remote (default learner for tictactoe, optimal for nim, alphabeta otherwise)"),
+		model:       fs.String("model", "learner_player.json", "model file for the learner"),
+		depth:       fs.Int("depth", 6, "search depth for alphabeta"),
+		iterations:  fs.Int("iterations", 2000, "playouts per move for mcts"),
+		heaps:       fs.String("heaps", "3,4,5", "comma separated starting heap sizes for nim and subtraction"),
+		take:        fs.String("take", "1,2,3", "comma separated counters a subtraction move may take"),
+		engine:      fs.String("engine", "", "engine for the remote player: a command to run, or tcp:HOST:PORT to connect to"),
+		boardConfig: boardFlags(fs),
+	}
+}
+
+// gameSpec is a game and an AI player chosen by name, from flags or from
+// a request to the server.
+type gameSpec struct {
+	Game       string
+	AI         string
+	Model      string
+	Depth      int
+	Iterations int
+	Heaps      string
+	Take       string
+	Engine     string // Only set from flags, never by clients of the server
+	Board      board.Config
+}
+
+// spec returns the choices made by the flags, exiting if the board or the
+// search effort is invalid.
+func (o *gameOptions) spec() gameSpec {
+	if *o.iterations < 1 {
+		fmt.Println("Invalid iterations:", *o.iterations, "is less than 1")
+		os.Exit(2)
+	}
+	return gameSpec{
+		Game:       *o.game,
+		AI:         *o.ai,
+		Model:      *o.model,
+		Depth:      *o.depth,
+		Iterations: *o.iterations,
+		Heaps:      *o.heaps,
+		Take:       *o.take,
+		Engine:     *o.engine,
+		Board:      o.boardConfig(),
+	}
+}
+
+// environment returns the chosen game, exiting if it is invalid.
+func (o *gameOptions) environment() env.Environment {
+	e, err := o.spec().environment()
+	if err != nil {
+		fmt.Println("Invalid game:", err)
+		os.Exit(2)
+	}
+	return e
+}
+
+// newAI creates the chosen AI player for seat.
+func (o *gameOptions) newAI(seat int) (player.Player, error) {
+	return o.spec().newAI(seat)
+}
+
+// environment returns the chosen game.
+func (s gameSpec) environment() (env.Environment, error) {
+	switch s.Game {
+	case "tictactoe":
+		return board.Environment{Config: s.Board}, nil
+	case "connect4":
+		return connect4.Environment{}, nil
+	case "ultimate":
+		return ultimate.Environment{}, nil
+	case "nim", "subtraction":
+		cfg, err := s.nimConfig()
+		if err != nil {
+			return nil, err
+		}
+		return nim.Environment{Config: cfg}, nil
+	}
+	return nil, errors.New("unknown game " + s.Game)
+}
+
+// nimConfig returns the nim or subtraction game chosen by the heaps, the
+// move sizes and the rules.
+func (s gameSpec) nimConfig() (nim.Config, error) {
+	cfg := nim.Config{}
+	var err error
+	if cfg.Heaps, err = parseInts(s.Heaps); err != nil {
+		return cfg, fmt.Errorf("invalid heaps: %w", err)
+	}
+	if s.Game == "subtraction" {
+		if cfg.Take, err = parseInts(s.Take); err != nil {
+			return cfg, fmt.Errorf("invalid take: %w", err)
+		}
+	}
+	switch s.Board.Rules {
+	case board.Standard:
+	case board.Misere:
+		cfg.Misere = true
+	default:
+		return cfg, errors.New("nim supports standard and misere rules")
+	}
+	return cfg, cfg.Validate()
+}
+
+// parseInts parses a comma separated list of integers.
+func parseInts(s string) ([]int, error) {
+	values := []int{}
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// hint returns the player that suggests moves to a human: perfect play
+// where the game is small enough to solve, a search otherwise.
+func (s gameSpec) hint(seat int) player.Player {
+	switch {
+	case s.Game == "nim" || s.Game == "subtraction":
+		return nim.NewOptimalPlayer(seat)
+	case s.Game == "tictactoe" && s.Board.Cells() <= 9:
+		return player.NewOraclePlayer(seat)
+	case s.Game == "tictactoe":
+		return player.NewMCTSPlayer(seat, s.Iterations)
+	}
+	return player.NewAlphaBetaPlayer(seat, s.Depth)
+}
+
+// protocol writes the game as the key=value pairs of the remote protocol.
+func (s gameSpec) protocol() string {
+	switch s.Game {
+	case "tictactoe":
+		return fmt.Sprintf("game=tictactoe width=%d height=%d k=%d rules=%s",
+			s.Board.Width, s.Board.Height, s.Board.K, s.Board.Rules)
+	case "nim":
+		return fmt.Sprintf("game=nim heaps=%s rules=%s", s.Heaps, s.Board.Rules)
+	case "subtraction":
+		return fmt.Sprintf("game=subtraction heaps=%s take=%s rules=%s", s.Heaps, s.Take, s.Board.Rules)
+	}
+	return "game=" + s.Game
+}
+
+// parseProtocol returns s with the choices of the key=value pairs of the
+// remote protocol applied.
+func (s gameSpec) parseProtocol(pairs []string) (gameSpec, error) {
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return s, fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		var err error
+		switch key {
+		case "game":
+			s.Game = value
+		case "ai":
+			s.AI = value
+		case "model":
+			s.Model = value
+		case "depth":
+			s.Depth, err = strconv.Atoi(value)
+		case "iterations":
+			s.Iterations, err = strconv.Atoi(value)
+		case "width":
+			s.Board.Width, err = strconv.Atoi(value)
+		case "height":
+			s.Board.Height, err = strconv.Atoi(value)
+		case "k":
+			s.Board.K, err = strconv.Atoi(value)
+		case "rules":
+			s.Board.Rules, err = board.ParseRules(value)
+		case "heaps":
+			s.Heaps = value
+		case "take":
+			s.Take = value
+		default:
+			return s, fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return s, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	if s.Game == "tictactoe" {
+		if err := s.Board.Validate(); err != nil {
+			return s, err
+		}
+	}
+	if s.AI == "remote" {
+		return s, errors.New("an engine cannot play a remote player")
+	}
+	return s, nil
+}
+
+// newRemote starts or connects to the engine of a remote player for seat.
+func (s gameSpec) newRemote(seat int) (*remote.RemotePlayer, error) {
+	if s.Engine == "" {
+		return nil, errors.New("the remote player needs an -engine")
+	}
+	e, err := s.environment()
+	if err != nil {
+		return nil, err
+	}
+	if addr, ok := strings.CutPrefix(s.Engine, "tcp:"); ok {
+		return remote.Dial(seat, e, s.protocol(), addr)
+	}
+	args := strings.Fields(s.Engine)
+	if len(args) == 0 {
+		return nil, errors.New("the remote player needs an -engine")
+	}
+	return remote.Start(seat, e, s.protocol(), args[0], args[1:]...)
+}
+
+// defaultAI returns the AI player used when none is chosen.
+func (s gameSpec) defaultAI() string {
+	if s.AI != "" {
+		return s.AI
+	}
+	switch s.Game {
+	case "tictactoe":
+		return "learner"
+	case "nim", "subtraction":
+		return "optimal"
+	}
+	return "alphabeta"
+}
+
+// newAI creates the chosen AI player for seat.
+func (s gameSpec) newAI(seat int) (player.Player, error) {
+	ai := s.defaultAI()
+	switch ai {
+	case "learner":
+		learner := player.NewLearnerPlayer(seat, 0.2, 0.01, "player")
+		if err := learner.LoadModel(s.Model); err != nil {
+			return nil, fmt.Errorf("loading model: %w", err)
+		}
+		return learner, nil
+	case "minimax":
+		return player.NewMinimaxPlayer(seat), nil
+	case "oracle":
+		return player.NewOraclePlayer(seat), nil
+	case "alphabeta":
+		return player.NewAlphaBetaPlayer(seat, s.Depth), nil
+	case "mcts":
+		if s.Iterations < 1 {
+			return nil, fmt.Errorf("mcts needs at least 1 iteration, not %d", s.Iterations)
+		}
+		return player.NewMCTSPlayer(seat, s.Iterations), nil
+	case "random":
+		return player.NewRandomPlayer(seat), nil
+	case "optimal":
+		if s.Game != "nim" && s.Game != "subtraction" {
+			return nil, errors.New("the optimal player only plays nim and subtraction")
+		}
+		return nim.NewOptimalPlayer(seat), nil
+	case "remote":
+		p, err := s.newRemote(seat)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	return nil, errors.New("unknown AI player: " + ai)
+}
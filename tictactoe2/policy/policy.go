@@ -0,0 +1,82 @@
+// Package policy holds the action selection and schedules shared by the
+// tabular learners: player.LearnerPlayer and the mdp agents.
+package policy
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Tolerance is how close two values must be to count as a tie.
+const Tolerance = 0.0001
+
+// Greedy returns the indices of every value that ties for the highest.
+func Greedy(values []float64) []int {
+	maxValue := math.Inf(-1)
+	best := []int{}
+	for i, value := range values {
+		if value > maxValue+Tolerance {
+			maxValue = value
+			best = []int{i}
+		} else if value >= maxValue-Tolerance {
+			best = append(best, i)
+			maxValue = math.Max(maxValue, value)
+		}
+	}
+	return best
+}
+
+// Explore reports whether an epsilon-greedy policy should take a random
+// action this time.
+func Explore(epsilon float64) bool {
+	return rand.Float64() < epsilon
+}
+
+// EpsilonGreedy returns the index of a random value with probability
+// epsilon, and otherwise of a random one of the highest values.
+func EpsilonGreedy(values []float64, epsilon float64) int {
+	if Explore(epsilon) {
+		return rand.Intn(len(values))
+	}
+	best := Greedy(values)
+	return best[rand.Intn(len(best))]
+}
+
+// Schedule gives a parameter such as the exploration rate or the learning
+// rate as a function of the number of episodes played.
+type Schedule interface {
+	At(episode int) float64
+}
+
+// Constant is a Schedule that never changes.
+type Constant float64
+
+func (c Constant) At(episode int) float64 {
+	return float64(c)
+}
+
+// Linear moves from Start to End over Episodes episodes, then stays at End.
+type Linear struct {
+	Start    float64
+	End      float64
+	Episodes int
+}
+
+func (l Linear) At(episode int) float64 {
+	if episode >= l.Episodes {
+		return l.End
+	}
+	return l.Start + (l.End-l.Start)*float64(episode)/float64(l.Episodes)
+}
+
+// Exponential starts at Start and is multiplied by Decay every episode,
+// never falling below Min.
+type Exponential struct {
+	Start float64
+	Decay float64
+	Min   float64
+}
+
+func (e Exponential) At(episode int) float64 {
+	return math.Max(e.Min, e.Start*math.Pow(e.Decay, float64(episode)))
+}
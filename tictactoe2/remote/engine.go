@@ -0,0 +1,168 @@
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// Factory creates the environment of the game described by the key=value
+// pairs of spec and the player for seat.
+type Factory func(spec []string, seat int) (env.Environment, player.Player, error)
+
+// engine is the game an engine is playing.
+type engine struct {
+	factory Factory
+	state   env.State // Current position, nil between games
+	player  player.Player
+}
+
+// Serve runs an engine, answering the commands read from r on w with the
+// players made by factory, until quit or the end of r.
+func Serve(r io.Reader, w io.Writer, factory Factory) error {
+	e := &engine{factory: factory}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		answer, err := e.handle(fields[0], fields[1:])
+		switch {
+		case err != nil:
+			_, err = fmt.Fprintf(w, "? %v\n", err)
+		case answer == "":
+			_, err = fmt.Fprintln(w, "=")
+		default:
+			_, err = fmt.Fprintf(w, "= %s\n", answer)
+		}
+		if err != nil {
+			return err
+		}
+		if fields[0] == "quit" {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// handle runs one command and returns the value of its answer.
+func (e *engine) handle(command string, args []string) (string, error) {
+	switch command {
+	case "tt":
+		if len(args) != 1 || args[0] != strconv.Itoa(Version) {
+			return "", fmt.Errorf("only protocol version %d is supported", Version)
+		}
+		return fmt.Sprintf("tt %d", Version), nil
+
+	case "newgame":
+		if len(args) == 0 {
+			return "", errors.New("usage: newgame SEAT SPEC... [ACTION...]")
+		}
+		seat, err := strconv.Atoi(args[0])
+		if err != nil || (seat != 1 && seat != 2) {
+			return "", errors.New("the seat must be 1 or 2")
+		}
+		spec, start, actions := []string{}, 1, []env.Action{}
+		for _, arg := range args[1:] {
+			if value, ok := strings.CutPrefix(arg, "start="); ok {
+				if start, err = strconv.Atoi(value); err != nil || (start != 1 && start != 2) {
+					return "", errors.New("start must be 1 or 2")
+				}
+			} else if strings.Contains(arg, "=") {
+				spec = append(spec, arg)
+			} else {
+				n, err := strconv.Atoi(arg)
+				if err != nil {
+					return "", fmt.Errorf("invalid action %q", arg)
+				}
+				actions = append(actions, env.Action(n))
+			}
+		}
+		environment, p, err := e.factory(spec, seat)
+		if err != nil {
+			return "", err
+		}
+		state := environment.NewState(start)
+		for _, a := range actions {
+			next, ok := state.Apply(a)
+			if !ok {
+				return "", fmt.Errorf("illegal move %d in the game's moves", a)
+			}
+			state = next
+		}
+		e.state, e.player = state, p
+		return "", nil
+
+	case "play":
+		a, err := e.action(args)
+		if err != nil {
+			return "", err
+		}
+		next, ok := e.state.Apply(a)
+		if !ok {
+			return "", fmt.Errorf("illegal move %d", a)
+		}
+		e.state = next
+		return "", nil
+
+	case "genmove":
+		if e.state == nil {
+			return "", errors.New("no game")
+		}
+		if len(args) == 0 || args[0] != e.state.Key() {
+			return "", errors.New("out of sync: the position differs from the engine's")
+		}
+		if e.state.Winner() != 0 {
+			return "", errors.New("the game is over")
+		}
+		a := e.player.MakeMove(e.state)
+		next, ok := e.state.Apply(a)
+		if !ok {
+			return "", fmt.Errorf("the player chose the illegal move %d", a)
+		}
+		e.state = next
+		return strconv.Itoa(int(a)), nil
+
+	case "result":
+		if e.state == nil {
+			return "", errors.New("no game")
+		}
+		if len(args) != 1 || (args[0] != "win" && args[0] != "loss") {
+			return "", errors.New("usage: result win|loss")
+		}
+		if args[0] == "win" {
+			e.player.Win()
+		} else {
+			e.player.Lose()
+		}
+		e.state = nil
+		return "", nil
+
+	case "quit":
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown command %q", command)
+}
+
+// action parses the single action argument of a command.
+func (e *engine) action(args []string) (env.Action, error) {
+	if e.state == nil {
+		return 0, errors.New("no game")
+	}
+	if len(args) != 1 {
+		return 0, errors.New("usage: play ACTION")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid action %q", args[0])
+	}
+	return env.Action(n), nil
+}
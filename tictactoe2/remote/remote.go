@@ -0,0 +1,322 @@
+// Package remote lets a program outside tt act as a player.Player over a
+// line-based text protocol, in the spirit of GTP and UCI, spoken over a
+// subprocess's stdin and stdout or a TCP connection.
+//
+// The controller, which runs the game, sends one command per line and the
+// engine, which plays one side, answers each with "=", followed by a space
+// and a value if the command returns one, or "? " and an error message.
+// Actions are the numbers of env.Action.
+//
+//	tt 1                      handshake and protocol version; answer "= tt 1"
+//	newgame SEAT SPEC... [ACTION...]
+//	                          start a game, playing SEAT (1 - X, 2 - O), from
+//	                          the position after the actions
+//	play ACTION               the opponent played ACTION
+//	genmove KEY ACTION...     choose a move: the state's key and the legal actions
+//	result win|loss           the engine won or lost the game
+//	quit                      end the session
+//
+// SPEC is the game as key=value pairs, e.g. "game=tictactoe width=3
+// height=3 k=3 rules=standard", named like the tt flags, and start=1 or 2,
+// the player who started. The actions after SPEC were played in turn from
+// the starting state, so a game can start from any position and the
+// controller can resume one after moves were taken back. The engine applies
+// its own moves from genmove, so play is only sent for the opponent's
+// moves. Players are not told of draws, so a drawn game ends without a
+// result and newgame starts the next one. An engine that does not track the
+// game can ignore newgame and play, and choose among the legal actions of
+// genmove.
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Version is the protocol version.
+const Version = 1
+
+// conn sends commands and reads answers.
+type conn struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+// command sends a command and returns the value of the answer.
+func (c *conn) command(format string, args ...any) (string, error) {
+	if _, err := fmt.Fprintf(c.w, format+"\n", args...); err != nil {
+		return "", err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "=":
+		return "", nil
+	case strings.HasPrefix(line, "= "):
+		return line[2:], nil
+	case strings.HasPrefix(line, "?"):
+		return "", errors.New("engine: " + strings.TrimSpace(line[1:]))
+	}
+	return "", fmt.Errorf("invalid answer %q", line)
+}
+
+// RemotePlayer is a player.Player whose moves are chosen by an engine.
+// The Player interface cannot return errors, so if the engine fails the
+// player records the error, returned by Err, and plays the first legal
+// action from then on.
+type RemotePlayer struct {
+	player int
+	env    env.Environment
+	spec   string
+	conn   conn
+	closer io.Closer
+	last   *position           // Position after the engine's last move, nil before a game
+	known  map[string]position // Positions of the game sent to the engine, by key
+	err    error
+}
+
+// position is a state and how the engine was told to reach it.
+type position struct {
+	state env.State
+	start int          // Player who started
+	line  []env.Action // Actions from the starting state
+}
+
+// then returns the position after a was played in p, reaching state.
+func (p position) then(a env.Action, state env.State) position {
+	line := append(append([]env.Action{}, p.line...), a)
+	return position{state: state, start: p.start, line: line}
+}
+
+// New creates a remote player for seat of games of e, described to the
+// engine by spec, that talks to the engine over rw. It performs the
+// handshake.
+func New(seat int, e env.Environment, spec string, rw io.ReadWriter) (*RemotePlayer, error) {
+	p := &RemotePlayer{
+		player: seat,
+		env:    e,
+		spec:   spec,
+		conn:   conn{r: bufio.NewReader(rw), w: rw},
+	}
+	if c, ok := rw.(io.Closer); ok {
+		p.closer = c
+	}
+	answer, err := p.conn.command("tt %d", Version)
+	if err != nil {
+		return nil, err
+	}
+	if answer != fmt.Sprintf("tt %d", Version) {
+		return nil, fmt.Errorf("engine speaks %q, want tt %d", answer, Version)
+	}
+	return p, nil
+}
+
+// Dial connects to an engine listening on the TCP address addr.
+func Dial(seat int, e env.Environment, spec, addr string) (*RemotePlayer, error) {
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := New(seat, e, spec, c)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// process joins the pipes of a subprocess.
+type process struct {
+	io.Reader
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (p *process) Close() error {
+	p.WriteCloser.Close()
+	return p.cmd.Wait()
+}
+
+// Start runs name with args as an engine, talking to it over its stdin
+// and stdout.
+func Start(seat int, e env.Environment, spec, name string, args ...string) (*RemotePlayer, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	proc := &process{Reader: stdout, WriteCloser: stdin, cmd: cmd}
+	p, err := New(seat, e, spec, proc)
+	if err != nil {
+		proc.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close ends the session and closes the connection.
+func (p *RemotePlayer) Close() error {
+	p.conn.command("quit")
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
+
+// Err returns the first error talking to the engine.
+func (p *RemotePlayer) Err() error {
+	return p.err
+}
+
+func (p *RemotePlayer) GetPlayer() int {
+	return p.player
+}
+
+func (p *RemotePlayer) SetPlayer(player int) {
+	p.player = player
+}
+
+// opponentMove returns the move that took from to state, if there is one.
+func opponentMove(from, state env.State) (env.Action, bool) {
+	if from == nil {
+		return 0, false
+	}
+	for _, a := range from.Actions() {
+		if next, ok := from.Apply(a); ok && next.Key() == state.Key() {
+			return a, true
+		}
+	}
+	return 0, false
+}
+
+// locate finds how to reach state: from its own record of its moves if it
+// is env.Recorded, or else as, or one move after, the starting state or a
+// position the engine was told of, as after moves are taken back.
+func (p *RemotePlayer) locate(state env.State) (position, error) {
+	if r, ok := state.(env.Recorded); ok {
+		return position{state: state, start: r.GetStart(), line: r.History()}, nil
+	}
+
+	start := position{state: p.env.NewState(1), start: 1}
+	if start.state.Key() == state.Key() {
+		return start, nil
+	}
+	if a, ok := opponentMove(start.state, state); ok {
+		return start.then(a, state), nil
+	}
+	if pos, ok := p.known[state.Key()]; ok {
+		return pos, nil
+	}
+	for _, pos := range p.known {
+		if a, ok := opponentMove(pos.state, state); ok {
+			return pos.then(a, state), nil
+		}
+	}
+	return position{}, errors.New("the position cannot be reached from the starting state or the positions of the game")
+}
+
+// remember records that the engine knows pos.
+func (p *RemotePlayer) remember(pos position) {
+	if p.known == nil {
+		p.known = map[string]position{}
+	}
+	p.known[pos.state.Key()] = pos
+}
+
+// sync tells the engine about the opponent's move since its last move, or
+// starts a new game at state if it does not follow from it, and returns
+// state's position.
+func (p *RemotePlayer) sync(state env.State) (position, error) {
+	if p.last != nil {
+		if a, ok := opponentMove(p.last.state, state); ok {
+			_, err := p.conn.command("play %d", a)
+			return p.last.then(a, state), err
+		}
+	}
+
+	pos, err := p.locate(state)
+	if err != nil {
+		return pos, err
+	}
+	command := fmt.Sprintf("newgame %d %s start=%d", p.player, p.spec, pos.start)
+	for _, a := range pos.line {
+		command += " " + strconv.Itoa(int(a))
+	}
+	_, err = p.conn.command("%s", command)
+	return pos, err
+}
+
+func (p *RemotePlayer) MakeMove(state env.State) env.Action {
+	actions := state.Actions()
+	if p.err == nil {
+		var pos position
+		if pos, p.err = p.sync(state); p.err == nil {
+			p.remember(pos)
+			var a env.Action
+			if a, p.err = p.genmove(state); p.err == nil {
+				next, _ := state.Apply(a)
+				after := pos.then(a, next)
+				p.last = &after
+				p.remember(after)
+				return a
+			}
+		}
+	}
+	p.last = nil
+	return actions[0]
+}
+
+// genmove asks the engine for its move in state, which it knows.
+func (p *RemotePlayer) genmove(state env.State) (env.Action, error) {
+
+	legal := []string{}
+	for _, a := range state.Actions() {
+		legal = append(legal, strconv.Itoa(int(a)))
+	}
+	answer, err := p.conn.command("genmove %s %s", state.Key(), strings.Join(legal, " "))
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil {
+		return 0, fmt.Errorf("invalid move %q", answer)
+	}
+	if _, ok := state.Apply(env.Action(n)); !ok {
+		return 0, fmt.Errorf("illegal move %d", n)
+	}
+	return env.Action(n), nil
+}
+
+func (p *RemotePlayer) result(outcome string) {
+	p.last = nil
+	p.known = nil
+	if p.err == nil {
+		_, p.err = p.conn.command("result %s", outcome)
+	}
+}
+
+func (p *RemotePlayer) Win() {
+	p.result("win")
+}
+
+func (p *RemotePlayer) Lose() {
+	p.result("loss")
+}
@@ -0,0 +1,153 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// startEngine serves an engine playing tic-tac-toe with the players made
+// by newPlayer and returns the controller's end of the connection.
+func startEngine(t *testing.T, newPlayer func(seat int) player.Player) net.Conn {
+	controller, engine := net.Pipe()
+	factory := func(spec []string, seat int) (env.Environment, player.Player, error) {
+		if strings.Join(spec, " ") != "game=tictactoe" {
+			return nil, nil, fmt.Errorf("unexpected spec %q", spec)
+		}
+		return board.Environment{Config: board.TicTacToe}, newPlayer(seat), nil
+	}
+	go func() {
+		Serve(engine, engine, factory)
+		engine.Close()
+	}()
+	t.Cleanup(func() { controller.Close() })
+	return controller
+}
+
+// Test remote players in both seats against minimax, over many games
+func TestRemoteMatch(t *testing.T) {
+	e := board.Environment{Config: board.TicTacToe}
+	for seat := 1; seat <= 2; seat++ {
+		conn := startEngine(t, func(seat int) player.Player { return player.NewMinimaxPlayer(seat) })
+		p, err := New(seat, e, "game=tictactoe", conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := eval.Match(e, p, player.NewMinimaxPlayer(3-seat), seat, 10)
+		if err := p.Err(); err != nil {
+			t.Fatalf("seat %d: %v", seat, err)
+		}
+		if r.Draws != 10 {
+			t.Errorf("seat %d: minimax against minimax should always draw, got %+v", seat, r)
+		}
+
+		conn = startEngine(t, func(seat int) player.Player { return player.NewRandomPlayer(seat) })
+		p, err = New(seat, e, "game=tictactoe", conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = eval.Match(e, p, player.NewMinimaxPlayer(3-seat), seat, 20)
+		if err := p.Err(); err != nil {
+			t.Fatalf("seat %d: %v", seat, err)
+		}
+		if r.Wins != 0 {
+			t.Errorf("seat %d: random play beat minimax: %+v", seat, r)
+		}
+		if err := p.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// Test the engine's answers to bad commands
+func TestEngineErrors(t *testing.T) {
+	conn := startEngine(t, func(seat int) player.Player { return player.NewRandomPlayer(seat) })
+	r := bufio.NewReader(conn)
+	for _, c := range []struct{ command, answer string }{
+		{"tt 2", "?"},
+		{"tt 1", "= tt 1"},
+		{"genmove 0 0", "?"},
+		{"newgame 3 game=tictactoe", "?"},
+		{"newgame 2 game=tictactoe", "="},
+		{"play 9", "?"},
+		{"play 4", "="},
+		{"genmove 0 0 1 2", "?"},
+		{"bogus", "?"},
+		{"quit", "="},
+	} {
+		fmt.Fprintln(conn, c.command)
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("%s: %v", c.command, err)
+		}
+		if !strings.HasPrefix(line, c.answer) {
+			t.Errorf("%s: got %q, want %q", c.command, strings.TrimSpace(line), c.answer)
+		}
+	}
+}
+
+// Test a game started from a position other than the starting state
+func TestRemoteFromPosition(t *testing.T) {
+	b, err := board.Parse(board.TicTacToe, "X../.O./..X o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := startEngine(t, func(seat int) player.Player { return player.NewMinimaxPlayer(seat) })
+	p, err := New(2, board.Environment{Config: board.TicTacToe}, "game=tictactoe", conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	winner := game.NewGameState(b, player.NewMinimaxPlayer(1), p, true).Play()
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if winner != 3 {
+		t.Errorf("minimax against minimax from a drawn position: winner %d, want a draw", winner)
+	}
+}
+
+// unrecorded hides the move stack of a board, like states of games that
+// do not keep one.
+type unrecorded struct {
+	env.State
+}
+
+// Test that the engine is resynchronised after moves are taken back
+func TestRemoteAfterUndo(t *testing.T) {
+	for _, wrap := range []func(env.State) env.State{
+		func(s env.State) env.State { return s },
+		func(s env.State) env.State { return unrecorded{s} },
+	} {
+		conn := startEngine(t, func(seat int) player.Player { return player.NewMinimaxPlayer(seat) })
+		p, err := New(1, board.Environment{Config: board.TicTacToe}, "game=tictactoe", conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// X plays, O replies and X plays again, then O takes back its
+		// reply and plays elsewhere
+		s0 := board.NewBoard(1)
+		s1, _ := s0.Apply(p.MakeMove(wrap(s0)))
+		var replies []env.State
+		for _, a := range s1.Actions()[:2] {
+			next, _ := s1.Apply(a)
+			replies = append(replies, next)
+		}
+		p.MakeMove(wrap(replies[0]))
+		a := p.MakeMove(wrap(replies[1]))
+		if err := p.Err(); err != nil {
+			t.Fatalf("%T: %v", wrap(s0), err)
+		}
+		if _, ok := replies[1].Apply(a); !ok {
+			t.Errorf("%T: illegal move %d after the undo", wrap(s0), a)
+		}
+	}
+}
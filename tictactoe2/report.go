@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/metrics"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/report"
+)
+
+func writeReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	metricsPath := fs.String("metrics", "", "training metrics written by 'tt train -metrics' (.csv or .jsonl)")
+	model := fs.String("model", "learner_player.json", "model file for the move value heatmaps")
+	out := fs.String("out", "report.html", "HTML file to write")
+	fs.Parse(args)
+
+	records := []metrics.Record{}
+	if *metricsPath != "" {
+		var err error
+		records, err = metrics.Read(*metricsPath)
+		if err != nil {
+			fmt.Println("Error reading metrics:", err)
+			os.Exit(2)
+		}
+	}
+
+	lp := player.NewLearnerPlayer(1, 0, 0, "learner")
+	if err := lp.LoadModel(*model); err != nil {
+		fmt.Println("Error loading model:", err)
+		os.Exit(2)
+	}
+
+	fp, err := os.Create(*out)
+	if err != nil {
+		fmt.Println("Error creating report:", err)
+		os.Exit(2)
+	}
+	defer fp.Close()
+
+	if err := report.Write(fp, records, lp, report.KeyPositions()); err != nil {
+		fmt.Println("Error writing report:", err)
+		os.Exit(2)
+	}
+	fmt.Println("Report written to", *out)
+}
@@ -0,0 +1,233 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/metrics"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// Position is a board whose move values are drawn as a heatmap.
+type Position struct {
+	Title string
+	Board *board.Board
+}
+
+// KeyPositions returns the empty board followed by the position after each
+// of X's nine first moves.
+func KeyPositions() []Position {
+	empty := board.NewBoard(1)
+	positions := []Position{{Title: "Empty board, X to move", Board: empty}}
+	for _, action := range empty.GetPossibleMoves() {
+		b := empty.Clone()
+		b.MakeMove(action.X, action.Y, action.Player)
+		positions = append(positions, Position{
+			Title: fmt.Sprintf("X played %d %d, O to move", action.X, action.Y),
+			Board: b,
+		})
+	}
+	return positions
+}
+
+// series is one line of a chart.
+type series struct {
+	name   string
+	color  string
+	values []float64
+}
+
+// Write writes a self-contained HTML report with learning curves from
+// records and heatmaps of lp's move values for positions. records may be
+// empty, in which case only the heatmaps are drawn.
+func Write(w io.Writer, records []metrics.Record, lp *player.LearnerPlayer, positions []Position) error {
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Training report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.charts, .heatmaps { display: flex; flex-wrap: wrap; gap: 1em; }
+figure { margin: 0; }
+figcaption { font-size: 0.9em; text-align: center; }
+</style></head><body>
+<h1>Training report</h1>
+`)
+
+	if len(records) > 0 {
+		games := []float64{}
+		get := func(f func(r metrics.Record) float64) []float64 {
+			values := []float64{}
+			for _, r := range records {
+				values = append(values, f(r))
+			}
+			return values
+		}
+		for _, r := range records {
+			games = append(games, float64(r.Games))
+		}
+
+		sb.WriteString("<h2>Learning curves</h2>\n<div class=\"charts\">\n")
+		sb.WriteString(lineChart("Outcome rates", games, []series{
+			{"win", "#2a9d8f", get(func(r metrics.Record) float64 { return r.WinRate })},
+			{"draw", "#e9c46a", get(func(r metrics.Record) float64 { return r.DrawRate })},
+			{"loss", "#e76f51", get(func(r metrics.Record) float64 { return r.LossRate })},
+		}))
+		sb.WriteString(lineChart("Mean absolute TD error", games, []series{
+			{"td error", "#264653", get(func(r metrics.Record) float64 { return r.TDError })},
+		}))
+		sb.WriteString(lineChart("Model size", games, []series{
+			{"states", "#264653", get(func(r metrics.Record) float64 { return float64(r.ModelSize) })},
+		}))
+		sb.WriteString(lineChart("Exploration and learning rate", games, []series{
+			{"epsilon", "#2a9d8f", get(func(r metrics.Record) float64 { return r.Epsilon })},
+			{"learning rate", "#e76f51", get(func(r metrics.Record) float64 { return r.LearningRate })},
+		}))
+		sb.WriteString(lineChart("Games per second", games, []series{
+			{"games/s", "#264653", get(func(r metrics.Record) float64 { return r.GamesPerSecond })},
+		}))
+		sb.WriteString("</div>\n")
+	}
+
+	if lp != nil && len(positions) > 0 {
+		frozen := lp.Frozen()
+		sb.WriteString("<h2>Move values</h2>\n<div class=\"heatmaps\">\n")
+		for _, p := range positions {
+			frozen.SetPlayer(p.Board.NextPlayer())
+			sb.WriteString(heatmap(p, frozen.ActionValues(p.Board)))
+		}
+		sb.WriteString("</div>\n")
+	}
+
+	sb.WriteString("</body></html>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+const (
+	chartWidth  = 420
+	chartHeight = 260
+	margin      = 45
+)
+
+// lineChart draws series against xs as an SVG figure.
+func lineChart(title string, xs []float64, lines []series) string {
+	minX, maxX := bounds(xs)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, l := range lines {
+		lo, hi := bounds(l.values)
+		minY = math.Min(minY, lo)
+		maxY = math.Max(maxY, hi)
+	}
+	if minY > 0 {
+		minY = 0
+	}
+	if maxY == minY {
+		maxY = minY + 1
+	}
+	if maxX == minX {
+		maxX = minX + 1
+	}
+
+	plotW := float64(chartWidth - 2*margin)
+	plotH := float64(chartHeight - 2*margin)
+	px := func(x float64) float64 { return margin + (x-minX)/(maxX-minX)*plotW }
+	py := func(y float64) float64 { return margin + plotH - (y-minY)/(maxY-minY)*plotH }
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<figure><svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n", chartWidth, chartHeight)
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"20\" text-anchor=\"middle\" font-size=\"14\">%s</text>\n", chartWidth/2, html.EscapeString(title))
+
+	// axes
+	fmt.Fprintf(&sb, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"black\"/>\n", margin, chartHeight-margin, chartWidth-margin, chartHeight-margin)
+	fmt.Fprintf(&sb, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"black\"/>\n", margin, margin, margin, chartHeight-margin)
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"10\" text-anchor=\"end\">%s</text>\n", margin-4, chartHeight-margin, label(minY))
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"10\" text-anchor=\"end\">%s</text>\n", margin-4, margin+8, label(maxY))
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"10\">%s</text>\n", margin, chartHeight-margin+14, label(minX))
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"10\" text-anchor=\"end\">%s</text>\n", chartWidth-margin, chartHeight-margin+14, label(maxX))
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"10\" text-anchor=\"middle\">games</text>\n", chartWidth/2, chartHeight-margin+14)
+
+	for i, l := range lines {
+		points := []string{}
+		for j, v := range l.values {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", px(xs[j]), py(v)))
+		}
+		fmt.Fprintf(&sb, "<polyline fill=\"none\" stroke=\"%s\" stroke-width=\"1.5\" points=\"%s\"/>\n", l.color, strings.Join(points, " "))
+
+		// legend
+		y := chartHeight - margin + 28
+		x := margin + 110*i
+		fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"10\" height=\"10\" fill=\"%s\"/>\n", x, y-9, l.color)
+		fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"11\">%s</text>\n", x+14, y, html.EscapeString(l.name))
+	}
+
+	sb.WriteString("</svg></figure>\n")
+	return sb.String()
+}
+
+const cellSize = 60
+
+// heatmap draws the board as a grid coloured by the value of playing in each empty
+// cell. values are in the order of p.Board.Actions().
+func heatmap(p Position, values []float64) string {
+	cells := p.Board.Get()
+	cellValue := map[int]float64{}
+	for i, action := range p.Board.Actions() {
+		cellValue[p.Board.Index(p.Board.Coords(action))] = values[i]
+	}
+
+	var sb strings.Builder
+	w, h := p.Board.Width(), p.Board.Height()
+	fmt.Fprintf(&sb, "<figure><svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n", w*cellSize+2, h*cellSize+2)
+	for idx := range cells {
+		x := 1 + (idx%w)*cellSize
+		y := 1 + (idx/w)*cellSize
+		fill := "#ffffff"
+		text := ""
+		switch cells[idx] {
+		case 1:
+			text = "X"
+		case 2:
+			text = "O"
+		default:
+			fill = color(cellValue[idx])
+			text = fmt.Sprintf("%.2f", cellValue[idx])
+		}
+		fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\" stroke=\"black\"/>\n", x, y, cellSize, cellSize, fill)
+		fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\" dominant-baseline=\"middle\" font-size=\"16\">%s</text>\n", x+cellSize/2, y+cellSize/2, text)
+	}
+	sb.WriteString("</svg>\n")
+	fmt.Fprintf(&sb, "<figcaption>%s</figcaption></figure>\n", html.EscapeString(p.Title))
+	return sb.String()
+}
+
+// color maps a value in [0, 1] from red through yellow to green.
+func color(v float64) string {
+	v = math.Max(0, math.Min(1, v))
+	var r, g float64
+	if v < 0.5 {
+		r, g = 1, v*2
+	} else {
+		r, g = (1-v)*2, 1
+	}
+	return fmt.Sprintf("#%02x%02x60", int(r*255), int(g*200))
+}
+
+func bounds(values []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	if len(values) == 0 {
+		return 0, 1
+	}
+	return lo, hi
+}
+
+func label(v float64) string {
+	return fmt.Sprintf("%.3g", v)
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/metrics"
+)
+
+// Test that each cell of the heatmap shows its mark or the value of
+// playing there
+func TestHeatmapCells(t *testing.T) {
+	b, err := board.Parse(board.TicTacToe, "X../..O/... x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the value of playing at (x, y) is 0.yx, so a transposed grid shows the
+	// wrong values
+	values := []float64{}
+	for _, a := range b.Actions() {
+		x, y := b.Coords(a)
+		values = append(values, float64(10*y+x)/100)
+	}
+	want := [][]string{
+		{"X", "0.01", "0.02"},
+		{"0.10", "0.11", "O"},
+		{"0.20", "0.21", "0.22"},
+	}
+
+	// each cell is a rect followed by its text
+	got := map[[2]int]string{}
+	lines := strings.Split(heatmap(Position{Title: "test", Board: b}, values), "\n")
+	for i, line := range lines {
+		var x, y int
+		if _, err := fmt.Sscanf(line, `<rect x="%d" y="%d"`, &x, &y); err != nil || i+1 == len(lines) {
+			continue
+		}
+		text := lines[i+1]
+		text = text[strings.Index(text, ">")+1 : strings.LastIndex(text, "</text>")]
+		got[[2]int{(x - 1) / cellSize, (y - 1) / cellSize}] = text
+	}
+	for y, row := range want {
+		for x, text := range row {
+			if got[[2]int{x, y}] != text {
+				t.Errorf("cell %d %d shows %q; want %q", x, y, got[[2]int{x, y}], text)
+			}
+		}
+	}
+}
+
+// Test that reports of no or a single record are written without
+// dividing by zero
+func TestWriteFewRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []metrics.Record
+		charts  int
+	}{
+		{"no records", []metrics.Record{}, 0},
+		{"one record", []metrics.Record{{Games: 100, Phase: "X vs minimax", WinRate: 0.5, DrawRate: 0.5}}, 5},
+		{"one empty record", []metrics.Record{{}}, 5},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, tt.records, nil, nil); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		out := buf.String()
+		if strings.Contains(out, "NaN") || strings.Contains(out, "Inf") {
+			t.Errorf("%s: the report has non-finite coordinates", tt.name)
+		}
+		if n := strings.Count(out, "<svg"); n != tt.charts {
+			t.Errorf("%s: %d charts; want %d", tt.name, n, tt.charts)
+		}
+		if !strings.HasSuffix(out, "</body></html>\n") {
+			t.Errorf("%s: the report is not complete", tt.name)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/server"
+)
+
+// models loads learner models from a directory once and shares them
+// between sessions.
+type models struct {
+	dir string
+
+	mu     sync.Mutex
+	loaded map[string]*player.LearnerPlayer
+}
+
+// learner returns a frozen learner playing seat with the model in file
+// name. Frozen learners never write to the model, so they can share it.
+func (m *models) learner(name string, seat int) (*player.LearnerPlayer, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, errors.New("model must be a file name in the models directory")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lp, ok := m.loaded[name]
+	if !ok {
+		lp = player.NewLearnerPlayer(seat, 0, 0, "frozen")
+		if err := lp.LoadModel(filepath.Join(m.dir, name)); err != nil {
+			return nil, fmt.Errorf("loading model: %w", err)
+		}
+		m.loaded[name] = lp
+	}
+	frozen := lp.Frozen()
+	frozen.SetPlayer(seat)
+	return frozen, nil
+}
+
+// Limits on the search effort a client may ask of the server, so that a
+// request cannot hang it or run it out of memory.
+const (
+	maxDepth      = 8
+	maxIterations = 20000
+)
+
+// clamp returns v limited to [lo, hi].
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+// newSession creates the game and AI player of a server session.
+func (m *models) newSession(req server.CreateRequest, seat int) (env.Environment, player.Player, error) {
+	spec := gameSpec{
+		Game:       req.Game,
+		AI:         req.AI,
+		Model:      req.Model,
+		Depth:      req.Depth,
+		Iterations: req.Iterations,
+		Heaps:      req.Heaps,
+		Take:       req.Take,
+		Board:      board.Config{Width: req.Width, Height: req.Height, K: req.K},
+	}
+	if spec.Game == "" {
+		spec.Game = "tictactoe"
+	}
+	if spec.Model == "" {
+		spec.Model = "learner_player.json"
+	}
+	if spec.Depth == 0 {
+		spec.Depth = 6
+	}
+	if spec.Iterations == 0 {
+		spec.Iterations = 2000
+	}
+	if spec.Heaps == "" {
+		spec.Heaps = "3,4,5"
+	}
+	if spec.Take == "" {
+		spec.Take = "1,2,3"
+	}
+	if spec.Board.Width == 0 && spec.Board.Height == 0 && spec.Board.K == 0 {
+		spec.Board = board.TicTacToe
+	}
+	rules := req.Rules
+	if rules == "" {
+		rules = "standard"
+	}
+	var err error
+	if spec.Board.Rules, err = board.ParseRules(rules); err != nil {
+		return nil, nil, err
+	}
+	if spec.Game == "tictactoe" {
+		if err := spec.Board.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	spec.Depth = clamp(spec.Depth, 1, maxDepth)
+	spec.Iterations = clamp(spec.Iterations, 1, maxIterations)
+	switch spec.defaultAI() {
+	case "minimax", "oracle":
+		// full search is only feasible on small boards
+		if spec.Game != "tictactoe" || spec.Board.Cells() > board.TicTacToe.Cells() {
+			return nil, nil, fmt.Errorf("%s can only play tic-tac-toe boards of up to %d cells", spec.defaultAI(), board.TicTacToe.Cells())
+		}
+	case "remote":
+		return nil, nil, errors.New("remote engines cannot be played on the server")
+	}
+
+	e, err := spec.environment()
+	if err != nil {
+		return nil, nil, err
+	}
+	ai, err := m.newAI(spec, seat)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, ai, nil
+}
+
+// newAI creates the AI player of spec for seat, sharing learner models.
+func (m *models) newAI(spec gameSpec, seat int) (player.Player, error) {
+	if spec.defaultAI() == "learner" {
+		return m.learner(spec.Model, seat)
+	}
+	return spec.newAI(seat)
+}
+
+// values returns the value of each move of state to the player to move,
+// by the learner model chosen in req.
+func (m *models) values(req server.CreateRequest, state env.State) ([]float64, error) {
+	model := req.Model
+	if model == "" {
+		model = "learner_player.json"
+	}
+	lp, err := m.learner(model, state.Player())
+	if err != nil {
+		return nil, err
+	}
+	return lp.ActionValues(state), nil
+}
+
+// serve runs the HTTP game server.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	dir := fs.String("models", ".", "directory of the model files clients may choose")
+	ttl := fs.Duration("ttl", 30*time.Minute, "end sessions idle for longer than this")
+	maxSessions := fs.Int("max-sessions", 1000, "most sessions held at once")
+	fs.Parse(args)
+
+	m := &models{dir: *dir, loaded: map[string]*player.LearnerPlayer{}}
+	s := server.New(m.newSession, *ttl, *maxSessions)
+	s.SetValues(m.values)
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			s.Expire(now)
+		}
+	}()
+
+	fmt.Printf("Serving games on http://%s/\n", *addr)
+	if err := http.ListenAndServe(*addr, s.Handler()); err != nil {
+		fmt.Println("Error serving:", err)
+		os.Exit(1)
+	}
+}
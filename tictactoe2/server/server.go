@@ -0,0 +1,442 @@
+// Package server serves games against AI players over HTTP with JSON
+// requests and responses. Each session is a game.Game between a remote
+// human and an AI player, and many sessions can be played at once.
+//
+//	POST   /games             create a game from a CreateRequest
+//	GET    /games/{id}        get its state
+//	GET    /games/{id}/moves  list the legal moves
+//	POST   /games/{id}/moves  play a move from a MoveRequest; the AI replies
+//	POST   /games/{id}/undo   take back the human's last move and the replies
+//	GET    /games/{id}/values the learner's value of each legal move
+//	DELETE /games/{id}        end the session
+//
+// GET / serves a page for playing in a browser.
+package server
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// CreateRequest chooses the game and the AI player of a new session.
+// Fields left empty take the same defaults as the tt flags.
+type CreateRequest struct {
+	Game       string `json:"game"`       // tictactoe, connect4, ultimate, nim or subtraction
+	AI         string `json:"ai"`         // learner, minimax, oracle, alphabeta, mcts, random or optimal
+	Model      string `json:"model"`      // Model file of the learner
+	Depth      int    `json:"depth"`      // Search depth of alphabeta
+	Iterations int    `json:"iterations"` // Playouts per move of mcts
+	Width      int    `json:"width"`      // Board size and rules of tictactoe
+	Height     int    `json:"height"`
+	K          int    `json:"k"`
+	Rules      string `json:"rules"`
+	Heaps      string `json:"heaps"` // Heaps of nim, e.g. "3,4,5"
+	Take       string `json:"take"`  // Moves of the subtraction game, e.g. "1,2,3"
+	Human      string `json:"human"` // Side of the human, "X" (the default) or "O"
+}
+
+// MoveRequest is a move, either as an action number or as text in the
+// game's move format, e.g. "1 1".
+type MoveRequest struct {
+	Action *int   `json:"action,omitempty"`
+	Move   string `json:"move,omitempty"`
+}
+
+// Move is a legal move.
+type Move struct {
+	Action int    `json:"action"`
+	Text   string `json:"text"`
+}
+
+// Value is the value of a legal move to the player making it.
+type Value struct {
+	Move
+	Value float64 `json:"value"`
+}
+
+// Board is the grid of an m,n,k board: 0 empty, 1 X, 2 O, row by row.
+type Board struct {
+	Width  int   `json:"width"`
+	Height int   `json:"height"`
+	Cells  []int `json:"cells"`
+}
+
+// State describes a session.
+type State struct {
+	ID      string   `json:"id"`
+	Game    string   `json:"game"`
+	Human   int      `json:"human"`  // 1 - X, 2 - O
+	Player  int      `json:"player"` // Player to move
+	Winner  int      `json:"winner"` // 0 while playing, 1 or 2 for the winner, 3 for a draw
+	History []string `json:"history"`
+	Legal   []Move   `json:"legal"`
+	Board   *Board   `json:"board,omitempty"` // Only for m,n,k boards
+	Key     string   `json:"key"`
+	CanUndo bool     `json:"can_undo"` // Whether the human has a move to take back
+}
+
+// Factory creates the game and the AI player, seated as seat, of a new
+// session. Errors are reported to the client as bad requests.
+type Factory func(req CreateRequest, seat int) (env.Environment, player.Player, error)
+
+// ValuesFunc returns a learner's value of each action of state, in the
+// order of state.Actions(), using the model chosen by req.
+type ValuesFunc func(req CreateRequest, state env.State) ([]float64, error)
+
+// session is a game between a remote human and an AI player.
+type session struct {
+	mu       sync.Mutex
+	id       string
+	req      CreateRequest
+	env      env.Environment
+	game     *game.Game
+	players  [3]player.Player
+	human    int
+	ai       player.Player
+	history  []string
+	states   []env.State  // State before each move in history
+	lastUsed atomic.Int64 // Time of the last request in Unix nanoseconds, read without mu
+}
+
+// Server holds the sessions.
+type Server struct {
+	factory     Factory
+	values      ValuesFunc
+	ttl         time.Duration
+	maxSessions int
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// New creates a server that creates games with factory, ends sessions idle
+// for longer than ttl when Expire is called and holds at most maxSessions
+// sessions.
+func New(factory Factory, ttl time.Duration, maxSessions int) *Server {
+	return &Server{
+		factory:     factory,
+		ttl:         ttl,
+		maxSessions: maxSessions,
+		sessions:    map[string]*session{},
+	}
+}
+
+// SetValues enables GET /games/{id}/values, which answers with values.
+func (s *Server) SetValues(values ValuesFunc) {
+	s.values = values
+}
+
+//go:embed ui
+var ui embed.FS
+
+// Handler returns the HTTP handler of the API and the page.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /games", s.create)
+	mux.HandleFunc("GET /games/{id}", s.withSession(s.get))
+	mux.HandleFunc("GET /games/{id}/moves", s.withSession(s.legal))
+	mux.HandleFunc("POST /games/{id}/moves", s.withSession(s.move))
+	mux.HandleFunc("POST /games/{id}/undo", s.withSession(s.undo))
+	mux.HandleFunc("GET /games/{id}/values", s.withSession(s.valuesOf))
+	mux.HandleFunc("DELETE /games/{id}", s.delete)
+
+	page, _ := fs.Sub(ui, "ui")
+	mux.Handle("GET /", http.FileServerFS(page))
+	return mux
+}
+
+// Expire ends the sessions that have been idle for longer than the TTL
+// and returns how many it ended. It does not wait for sessions busy with a
+// request.
+func (s *Server) Expire(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ended := 0
+	for id, ss := range s.sessions {
+		if idle := now.Sub(time.Unix(0, ss.lastUsed.Load())); idle > s.ttl {
+			delete(s.sessions, id)
+			ended++
+		}
+	}
+	return ended
+}
+
+// seat stands in for the remote human in game.Game. Its moves arrive
+// through the API, so MakeMove is never called.
+type seat struct {
+	player int
+}
+
+func (p *seat) MakeMove(state env.State) env.Action {
+	panic("server: MakeMove called for the remote player")
+}
+
+func (p *seat) Win()                 {}
+func (p *seat) Lose()                {}
+func (p *seat) GetPlayer() int       { return p.player }
+func (p *seat) SetPlayer(player int) { p.player = player }
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	req := CreateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request: "+err.Error()))
+		return
+	}
+
+	human := 1
+	switch req.Human {
+	case "", "X", "x":
+	case "O", "o":
+		human = 2
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("human must be X or O"))
+		return
+	}
+
+	e, ai, err := s.factory(req, 3-human)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	players := [3]player.Player{}
+	players[human] = &seat{player: human}
+	players[3-human] = ai
+	ss := &session{
+		id:      newID(),
+		req:     req,
+		env:     e,
+		game:    game.NewGameState(e.NewState(1), players[1], players[2], true),
+		players: players,
+		human:   human,
+		ai:      ai,
+		history: []string{},
+	}
+	ss.lastUsed.Store(time.Now().UnixNano())
+
+	s.mu.Lock()
+	if len(s.sessions) >= s.maxSessions {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many sessions"))
+		return
+	}
+	s.sessions[ss.id] = ss
+	s.mu.Unlock()
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := ss.playAI(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, ss.state())
+}
+
+// withSession looks up the session of the request and calls handler with
+// it locked.
+func (s *Server) withSession(handler func(w http.ResponseWriter, r *http.Request, ss *session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		ss, ok := s.sessions[r.PathValue("id")]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("no such game"))
+			return
+		}
+
+		ss.lastUsed.Store(time.Now().UnixNano())
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		handler(w, r, ss)
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, ss *session) {
+	writeJSON(w, http.StatusOK, ss.state())
+}
+
+func (s *Server) legal(w http.ResponseWriter, r *http.Request, ss *session) {
+	writeJSON(w, http.StatusOK, ss.legal())
+}
+
+func (s *Server) move(w http.ResponseWriter, r *http.Request, ss *session) {
+	req := MoveRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request: "+err.Error()))
+		return
+	}
+
+	state := ss.game.GetState()
+	if state.Winner() != 0 {
+		writeError(w, http.StatusConflict, errors.New("the game is over"))
+		return
+	}
+
+	var action env.Action
+	if req.Action != nil {
+		action = env.Action(*req.Action)
+	} else {
+		var err error
+		if action, err = state.ParseAction(req.Move); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if !ss.apply(action) {
+		writeError(w, http.StatusBadRequest, errors.New("illegal move"))
+		return
+	}
+
+	if err := ss.playAI(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ss.state())
+}
+
+func (s *Server) undo(w http.ResponseWriter, r *http.Request, ss *session) {
+	if !ss.undo() {
+		writeError(w, http.StatusConflict, errors.New("no move to take back"))
+		return
+	}
+	writeJSON(w, http.StatusOK, ss.state())
+}
+
+func (s *Server) valuesOf(w http.ResponseWriter, r *http.Request, ss *session) {
+	if s.values == nil {
+		writeError(w, http.StatusNotFound, errors.New("values are not available"))
+		return
+	}
+	state := ss.game.GetState()
+	values := []Value{}
+	if state.Winner() != 0 {
+		writeJSON(w, http.StatusOK, values)
+		return
+	}
+	v, err := s.values(ss.req, state)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	for i, a := range state.Actions() {
+		values = append(values, Value{Move: Move{Action: int(a), Text: state.FormatAction(a)}, Value: v[i]})
+	}
+	writeJSON(w, http.StatusOK, values)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.sessions[r.PathValue("id")]
+	delete(s.sessions, r.PathValue("id"))
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("no such game"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply plays action and records it in the history.
+func (ss *session) apply(action env.Action) bool {
+	state := ss.game.GetState()
+	if !ss.game.Apply(action) {
+		return false
+	}
+	ss.history = append(ss.history, state.FormatAction(action))
+	ss.states = append(ss.states, state)
+	return true
+}
+
+// lastHumanMove returns the index in the history of the human's last move,
+// or -1 if they have not moved.
+func (ss *session) lastHumanMove() int {
+	for i := len(ss.states) - 1; i >= 0; i-- {
+		if ss.states[i].Player() == ss.human {
+			return i
+		}
+	}
+	return -1
+}
+
+// undo returns the game to the state before the human's last move.
+func (ss *session) undo() bool {
+	i := ss.lastHumanMove()
+	if i < 0 {
+		return false
+	}
+	ss.game = game.NewGameState(ss.states[i], ss.players[1], ss.players[2], true)
+	ss.history = ss.history[:i]
+	ss.states = ss.states[:i]
+	return true
+}
+
+// playAI lets the AI move until it is the human's turn or the game is over.
+func (ss *session) playAI() error {
+	for ss.game.GetState().Winner() == 0 && ss.game.GetState().Player() != ss.human {
+		if !ss.apply(ss.ai.MakeMove(ss.game.GetState())) {
+			return errors.New("the AI player made an illegal move")
+		}
+	}
+	return nil
+}
+
+func (ss *session) legal() []Move {
+	state := ss.game.GetState()
+	moves := []Move{}
+	if state.Winner() != 0 {
+		return moves
+	}
+	for _, a := range state.Actions() {
+		moves = append(moves, Move{Action: int(a), Text: state.FormatAction(a)})
+	}
+	return moves
+}
+
+func (ss *session) state() State {
+	state := ss.game.GetState()
+	st := State{
+		ID:      ss.id,
+		Game:    ss.env.Name(),
+		Human:   ss.human,
+		Player:  state.Player(),
+		Winner:  state.Winner(),
+		History: ss.history,
+		Legal:   ss.legal(),
+		Key:     state.Key(),
+		CanUndo: ss.lastHumanMove() >= 0,
+	}
+	if b, ok := state.(*board.Board); ok {
+		st.Board = &Board{Width: b.Width(), Height: b.Height(), Cells: b.Get()}
+	}
+	return st
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
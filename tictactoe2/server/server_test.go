@@ -0,0 +1,162 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+func newTestServer() *httptest.Server {
+	factory := func(req CreateRequest, seat int) (env.Environment, player.Player, error) {
+		return board.Environment{Config: board.TicTacToe}, player.NewMinimaxPlayer(seat), nil
+	}
+	return httptest.NewServer(New(factory, time.Hour, 100).Handler())
+}
+
+// call sends body as JSON and decodes the response into out.
+func call(t *testing.T, method, url string, body any, out any) int {
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, url, bytes.NewReader(data))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+// Test a game from creation to the end against minimax
+func TestPlayGame(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	st := State{}
+	if code := call(t, "POST", ts.URL+"/games", CreateRequest{Human: "O"}, &st); code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+	if st.Human != 2 || len(st.History) != 1 || st.Player != 2 {
+		t.Fatalf("the AI should have moved first as X: %+v", st)
+	}
+
+	url := ts.URL + "/games/" + st.ID
+	taken := st.Board.Cells[0] != 0
+	if code := call(t, "POST", url+"/moves", MoveRequest{Move: "0 0"}, nil); taken && code != http.StatusBadRequest {
+		t.Errorf("move to a taken cell: status %d", code)
+	}
+
+	for st.Winner == 0 {
+		moves := []Move{}
+		call(t, "GET", url+"/moves", nil, &moves)
+		if code := call(t, "POST", url+"/moves", MoveRequest{Action: &moves[0].Action}, &st); code != http.StatusOK {
+			t.Fatalf("move %s: status %d", moves[0].Text, code)
+		}
+	}
+	if st.Winner == 2 {
+		t.Error("minimax lost")
+	}
+	if code := call(t, "POST", url+"/moves", MoveRequest{Action: new(int)}, nil); code != http.StatusConflict {
+		t.Errorf("move after the end: status %d", code)
+	}
+
+	if code := call(t, "DELETE", url, nil, nil); code != http.StatusNoContent {
+		t.Errorf("delete: status %d", code)
+	}
+	if code := call(t, "GET", url, nil, nil); code != http.StatusNotFound {
+		t.Errorf("get after delete: status %d", code)
+	}
+}
+
+// Test that concurrent sessions do not interfere
+func TestConcurrentSessions(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st := State{}
+			call(t, "POST", ts.URL+"/games", CreateRequest{}, &st)
+			for st.Winner == 0 {
+				moves := st.Legal
+				call(t, "POST", ts.URL+"/games/"+st.ID+"/moves", MoveRequest{Action: &moves[len(moves)-1].Action}, &st)
+			}
+			if len(st.History) < 5 {
+				t.Errorf("game %s ended after %d moves", st.ID, len(st.History))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Test that undo takes back the human's move and the AI's reply
+func TestUndo(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	st := State{}
+	call(t, "POST", ts.URL+"/games", CreateRequest{Human: "O"}, &st)
+	url := ts.URL + "/games/" + st.ID
+	if st.CanUndo {
+		t.Error("can undo before the human moved")
+	}
+	before := st.Key
+
+	call(t, "POST", url+"/moves", MoveRequest{Action: &st.Legal[0].Action}, &st)
+	if code := call(t, "POST", url+"/undo", nil, &st); code != http.StatusOK {
+		t.Fatalf("undo: status %d", code)
+	}
+	if st.Key != before || len(st.History) != 1 || st.Player != 2 {
+		t.Errorf("undo returned to %s after %v, want %s", st.Key, st.History, before)
+	}
+	if code := call(t, "POST", url+"/undo", nil, nil); code != http.StatusConflict {
+		t.Errorf("second undo: status %d", code)
+	}
+}
+
+// Test that Expire ends idle sessions without waiting for busy ones
+func TestExpire(t *testing.T) {
+	factory := func(req CreateRequest, seat int) (env.Environment, player.Player, error) {
+		return board.Environment{Config: board.TicTacToe}, player.NewMinimaxPlayer(seat), nil
+	}
+	s := New(factory, time.Minute, 100)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	idle, busy := State{}, State{}
+	call(t, "POST", ts.URL+"/games", CreateRequest{}, &idle)
+	call(t, "POST", ts.URL+"/games", CreateRequest{}, &busy)
+
+	// a session computing a move holds its lock
+	s.mu.Lock()
+	held := s.sessions[busy.ID]
+	s.mu.Unlock()
+	held.mu.Lock()
+	defer held.mu.Unlock()
+
+	done := make(chan int)
+	go func() { done <- s.Expire(time.Now().Add(2 * time.Minute)) }()
+	select {
+	case ended := <-done:
+		if ended != 2 {
+			t.Errorf("Expire ended %d sessions, want 2", ended)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expire waited for a busy session")
+	}
+	if code := call(t, "GET", ts.URL+"/games/"+idle.ID, nil, nil); code != http.StatusNotFound {
+		t.Errorf("expired session answered %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/nim"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// solve prints the game-theoretic value of the starting position and the
+// optimal first moves: by full minimax search on tic-tac-toe boards of up
+// to 9 cells and by the nim-sum for nim and subtraction. Larger games are
+// refused, as their search does not finish.
+func solve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	opts := gameFlags(fs)
+	fs.Parse(args)
+
+	spec := opts.spec()
+	e := opts.environment()
+	state := e.NewState(1)
+
+	var best []env.Action
+	var value int
+	switch {
+	case spec.Game == "nim" || spec.Game == "subtraction":
+		s := state.(*nim.State)
+		best, value = nim.OptimalMoves(s), -1
+		if nim.Winning(s) {
+			value = 1
+		}
+	case spec.Game == "tictactoe" && spec.Board.Cells() <= board.TicTacToe.Cells():
+		best, value = player.NewOraclePlayer(1).OptimalMoves(state)
+	default:
+		fmt.Printf("Error solving %s: only tic-tac-toe boards of up to %d cells, nim and subtraction can be solved\n",
+			e.Name(), board.TicTacToe.Cells())
+		os.Exit(2)
+	}
+	moves := []string{}
+	for _, a := range best {
+		moves = append(moves, state.FormatAction(a))
+	}
+
+	fmt.Printf("%s: %s for X with perfect play. Optimal first moves: %s\n",
+		e.Name(), valueNames[value], strings.Join(moves, ", "))
+}
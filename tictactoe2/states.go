@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/statespace"
+)
+
+// states prints the number of positions reachable on a board by depth and
+// by outcome, all of them and up to symmetry, and how many of the
+// positions each side reaches by its moves the learner's model holds.
+func states(args []string) {
+	fs := flag.NewFlagSet("states", flag.ExitOnError)
+	start := fs.String("start", "x", "player who starts, x or o")
+	model := fs.String("model", "learner_player.json", "model file to measure the coverage of")
+	boardConfig := boardFlags(fs)
+	fs.Parse(args)
+
+	cfg := boardConfig()
+	if cfg.Cells() > board.TicTacToe.Cells() {
+		fmt.Println("Error enumerating states: boards of more than 9 cells have too many positions")
+		os.Exit(2)
+	}
+	first := map[string]int{"x": 1, "o": 2}[*start]
+	if first == 0 {
+		fmt.Println("Invalid start:", *start, "is not x or o")
+		os.Exit(2)
+	}
+
+	space := statespace.Enumerate(board.NewBoardConfig(cfg, first))
+	reduced := space.Reduce()
+
+	fmt.Printf("Positions reachable on %s, %s starting\n\n", cfg, []string{"", "X", "O"}[first])
+	fmt.Printf("%-6s %10s %15s\n", "Depth", "Positions", "Up to symmetry")
+	reducedByDepth := reduced.ByDepth()
+	for depth, n := range space.ByDepth() {
+		fmt.Printf("%-6d %10d %15d\n", depth, n, reducedByDepth[depth])
+	}
+	fmt.Printf("%-6s %10d %15d\n\n", "Total", space.Len(), reduced.Len())
+
+	o, r := space.Outcomes(), reduced.Outcomes()
+	fmt.Printf("Unfinished %d (%d), X wins %d (%d), O wins %d (%d), draws %d (%d)\n",
+		o.Unfinished, r.Unfinished, o.XWins, r.XWins, o.OWins, r.OWins, o.Draws, r.Draws)
+
+	lp := player.NewLearnerPlayer(1, 0, 0, "frozen")
+	if err := lp.LoadModel(*model); err != nil {
+		fmt.Println("No coverage:", err)
+		return
+	}
+	fmt.Println()
+	for seat := 1; seat <= 2; seat++ {
+		moved := space.MovedBy(seat)
+		visited := moved.Coverage(lp.HasValue)
+		fmt.Printf("%s: the model has visited %d of the %d positions reachable by %s's moves (%.1f%%)\n",
+			*model, visited, moved.Len(), []string{"", "X", "O"}[seat], 100*float64(visited)/float64(moved.Len()))
+	}
+}
@@ -0,0 +1,168 @@
+// Package statespace enumerates the positions reachable from a starting
+// position and counts them by depth, by outcome and up to symmetry. The
+// set of positions is the denominator of coverage: how much of the game a
+// learner's model has seen.
+package statespace
+
+import (
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Position is a reachable position and its distance from the root.
+type Position struct {
+	env.State
+	Depth int // Moves from the root
+}
+
+// Space is the set of positions reachable from a root position, each
+// once, in order of depth.
+type Space struct {
+	Positions []Position
+	index     map[string]int // Index in Positions, by State.Key
+}
+
+// Symmetric is a state that can name its class of positions equal up to a
+// symmetry of the game, such as board.Board.
+type Symmetric interface {
+	CanonicalKey() string
+}
+
+// Enumerate returns every position reachable from root, including root
+// and finished games. It holds them all in memory, so it is only feasible
+// for small games.
+func Enumerate(root env.State) *Space {
+	s := &Space{index: map[string]int{}}
+	s.add(Position{State: root})
+	for i := 0; i < len(s.Positions); i++ {
+		pos := s.Positions[i]
+		if pos.Winner() != 0 {
+			continue
+		}
+		for _, action := range pos.Actions() {
+			next, _ := pos.Apply(action)
+			s.add(Position{State: next, Depth: pos.Depth + 1})
+		}
+	}
+	return s
+}
+
+// add adds pos unless the space holds it already.
+func (s *Space) add(pos Position) {
+	if _, ok := s.index[pos.Key()]; ok {
+		return
+	}
+	s.index[pos.Key()] = len(s.Positions)
+	s.Positions = append(s.Positions, pos)
+}
+
+// Len returns the number of positions.
+func (s *Space) Len() int {
+	return len(s.Positions)
+}
+
+// Contains reports whether the position with key is in the space.
+func (s *Space) Contains(key string) bool {
+	_, ok := s.index[key]
+	return ok
+}
+
+// Filter returns the space of the positions keep accepts.
+func (s *Space) Filter(keep func(Position) bool) *Space {
+	f := &Space{index: map[string]int{}}
+	for _, pos := range s.Positions {
+		if keep(pos) {
+			f.add(pos)
+		}
+	}
+	return f
+}
+
+// Reduce returns the space with one position of each class of positions
+// equal up to symmetry, the first of the class. States that are not
+// Symmetric are classes of their own.
+func (s *Space) Reduce() *Space {
+	classes := map[string]bool{}
+	return s.Filter(func(pos Position) bool {
+		class := pos.Key()
+		if sym, ok := pos.State.(Symmetric); ok {
+			class = sym.CanonicalKey()
+		}
+		if classes[class] {
+			return false
+		}
+		classes[class] = true
+		return true
+	})
+}
+
+// ToMove returns the space of the unfinished positions with player to
+// move, the positions in which player chooses a move.
+func (s *Space) ToMove(player int) *Space {
+	return s.Filter(func(pos Position) bool {
+		return pos.Winner() == 0 && pos.Player() == player
+	})
+}
+
+// MovedBy returns the space of the positions reached by a move of player,
+// including those that end the game. These are the afterstates a learner
+// playing player values.
+func (s *Space) MovedBy(player int) *Space {
+	return s.Filter(func(pos Position) bool {
+		return pos.Depth > 0 && pos.Player() == 3-player
+	})
+}
+
+// ByDepth returns the number of positions at each depth.
+func (s *Space) ByDepth() []int {
+	counts := []int{}
+	for _, pos := range s.Positions {
+		for len(counts) <= pos.Depth {
+			counts = append(counts, 0)
+		}
+		counts[pos.Depth]++
+	}
+	return counts
+}
+
+// Outcomes counts positions by the state of the game.
+type Outcomes struct {
+	Unfinished int
+	XWins      int
+	OWins      int
+	Draws      int
+}
+
+// Finished returns the number of finished games.
+func (o Outcomes) Finished() int {
+	return o.XWins + o.OWins + o.Draws
+}
+
+// Outcomes counts the positions by the state of the game.
+func (s *Space) Outcomes() Outcomes {
+	o := Outcomes{}
+	for _, pos := range s.Positions {
+		switch pos.Winner() {
+		case 0:
+			o.Unfinished++
+		case 1:
+			o.XWins++
+		case 2:
+			o.OWins++
+		default:
+			o.Draws++
+		}
+	}
+	return o
+}
+
+// Coverage returns the number of positions in the space for which visited
+// reports true, such as the positions a learner's model holds values for.
+func (s *Space) Coverage(visited func(key string) bool) int {
+	n := 0
+	for _, pos := range s.Positions {
+		if visited(pos.Key()) {
+			n++
+		}
+	}
+	return n
+}
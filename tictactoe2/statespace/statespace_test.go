@@ -0,0 +1,53 @@
+package statespace
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+)
+
+func TestTicTacToe(t *testing.T) {
+	s := Enumerate(board.NewBoard(1))
+	if s.Len() != 5478 {
+		t.Errorf("%d positions, want 5478", s.Len())
+	}
+	if want := []int{1, 9, 72, 252, 756, 1260, 1520, 1140, 390, 78}; !reflect.DeepEqual(s.ByDepth(), want) {
+		t.Errorf("ByDepth = %v, want %v", s.ByDepth(), want)
+	}
+	if want := (Outcomes{Unfinished: 4520, XWins: 626, OWins: 316, Draws: 16}); s.Outcomes() != want {
+		t.Errorf("Outcomes = %+v, want %+v", s.Outcomes(), want)
+	}
+
+	r := s.Reduce()
+	if r.Len() != 765 {
+		t.Errorf("%d positions up to symmetry, want 765", r.Len())
+	}
+	if want := (Outcomes{Unfinished: 627, XWins: 91, OWins: 44, Draws: 3}); r.Outcomes() != want {
+		t.Errorf("Outcomes up to symmetry = %+v, want %+v", r.Outcomes(), want)
+	}
+
+	if n := s.ToMove(1).Len() + s.ToMove(2).Len(); n != 4520 {
+		t.Errorf("%d positions to move, want 4520", n)
+	}
+	if n := s.MovedBy(1).Len() + s.MovedBy(2).Len(); n != s.Len()-1 {
+		t.Errorf("%d positions after a move, want %d", n, s.Len()-1)
+	}
+}
+
+func TestBitboard(t *testing.T) {
+	s := Enumerate(board.NewBitboard(2))
+	b := Enumerate(board.NewBoard(2))
+	if s.Len() != b.Len() || s.Reduce().Len() != b.Reduce().Len() {
+		t.Errorf("bitboard has %d positions, %d up to symmetry, board %d and %d",
+			s.Len(), s.Reduce().Len(), b.Len(), b.Reduce().Len())
+	}
+	for _, pos := range s.Positions {
+		if !b.Contains(pos.Key()) {
+			t.Fatalf("board positions lack %v", pos)
+		}
+	}
+	if n := s.Coverage(b.Contains); n != s.Len() {
+		t.Errorf("Coverage = %d, want %d", n, s.Len())
+	}
+}
@@ -1,141 +1,163 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
 	"github.com/param108/reinforcement-learning/tictactoe2/game"
 	"github.com/param108/reinforcement-learning/tictactoe2/player"
+	"github.com/param108/reinforcement-learning/tictactoe2/remote"
 )
 
 func main() {
 
 	if os.Args[1] == "train" {
-		// Create 2 human players
-		player1 := player.NewLearnerPlayer(1, 0.2, 0.1, "learner")
-		wins := 0
-		lose := 0
-		draw := 0
-		for i := 0; i < 10000; i++ {
-			fmt.Print("\r", "Playing as X", i)
-			player2 := player.NewMinimaxPlayer(2)
-			// Create a new game with player 1 starting
-			g := game.NewGame(1, player1, player2, true)
-			// Play the game
-			result := g.Play()
-			if result == player1.GetPlayer() {
-				wins++
-			} else if result == 3 {
-				draw++
-			} else {
-				lose++
-			}
-		}
-		fmt.Println("\nTraining as X finished. Wins:", wins, "Draws:", draw, "Losses:", lose)
-
-		wins = 0
-		lose = 0
-		draw = 0
-
-		player1.SetPlayer(2)
-		for i := 0; i < 10000; i++ {
-			fmt.Print("\r", "Playing as O", i)
-			player2 := player.NewMinimaxPlayer(1)
-			// Create a new game with player 1 starting
-			g := game.NewGame(1, player2, player1, true)
-			// Play the game
-			result := g.Play()
-			if result == player1.GetPlayer() {
-				wins++
-			} else if result == 3 {
-				draw++
-			} else {
-				lose++
-			}
-		}
-		fmt.Println("\nTraining as O finished. Wins:", wins, "Draws:", draw, "Losses:", lose)
-
-		player1.SetPlayer(1)
-
-		wins = 0
-		lose = 0
-		draw = 0
-		for i := 0; i < 10000; i++ {
-			fmt.Print("\r", "Playing as X", i)
-			player2 := player.NewLearnerPlayer(2, 0.2, 0.1, "learner")
-			// Create a new game with player 1 starting
-			g := game.NewGame(1, player1, player2, true)
-			// Play the game
-			result := g.Play()
-			if result == player1.GetPlayer() {
-				wins++
-			} else if result == 3 {
-				draw++
-			} else {
-				lose++
-			}
-		}
-		fmt.Println("\nTraining as X finished. Wins:", wins, "Draws:", draw, "Losses:", lose)
-
-		wins = 0
-		lose = 0
-		draw = 0
-
-		player1.SetPlayer(2)
-		for i := 0; i < 10000; i++ {
-			fmt.Print("\r", "Playing as O", i)
-			player2 := player.NewLearnerPlayer(1, 0.2, 0.1, "learner")
-			// Create a new game with player 1 starting
-			g := game.NewGame(1, player2, player1, true)
-			// Play the game
-			result := g.Play()
-			if result == player1.GetPlayer() {
-				wins++
-			} else if result == 3 {
-				draw++
-			} else {
-				lose++
-			}
-		}
-		fmt.Println("\nTraining as O finished. Wins:", wins, "Draws:", draw, "Losses:", lose)
+		train(os.Args[2:])
+		return
+	}
 
-		player1.SaveModel("learner_player.json")
+	if os.Args[1] == "audit" {
+		audit(os.Args[2:])
 		return
 	}
 
-	if os.Args[1] == "playX" {
-		// Create a human player
-		player1 := player.NewHumanPlayer(1)
-		player2 := player.NewLearnerPlayer(2, 0.2, 0.01, "player")
-		if err := player2.LoadModel("learner_player.json"); err != nil {
-			fmt.Println("Error loading model:", err)
-			return
-		}
+	if os.Args[1] == "report" {
+		writeReport(os.Args[2:])
+		return
+	}
 
-		g := game.NewGame(1, player1, player2, false)
-		result := g.Play()
+	if os.Args[1] == "solve" {
+		solve(os.Args[2:])
+		return
+	}
 
-		g.GetBoard().Print()
-		fmt.Println("Game result:", result)
+	if os.Args[1] == "mdp" {
+		solveMDP(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "blackjack" {
+		learnBlackjack(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "inspect" {
+		inspect(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "states" {
+		states(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "engine" {
+		engine(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "match" {
+		match(os.Args[2:])
+		return
+	}
+
+	if os.Args[1] == "playX" {
+		play(os.Args[2:], 1)
 		return
 	}
 
 	if os.Args[1] == "playO" {
-		// Create a human player
-		player2 := player.NewHumanPlayer(2)
-		player1 := player.NewLearnerPlayer(1, 0.2, 0.01, "player")
-		if err := player1.LoadModel("learner_player.json"); err != nil {
-			fmt.Println("Error loading model:", err)
-			return
-		}
+		play(os.Args[2:], 2)
+		return
+	}
 
-		g := game.NewGame(1, player1, player2, false)
-		result := g.Play()
+	fmt.Println("Invalid command. Use 'train', 'audit', 'report', 'solve', 'mdp', 'blackjack', 'inspect', 'states', 'serve', 'engine', 'match', 'playX', or 'playO'.")
+}
 
-		g.GetBoard().Print()
-		fmt.Println("Game result:", result)
+// play plays a human, seated as human (1 - X, 2 - O), against an AI player.
+func play(args []string, human int) {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	opts := gameFlags(fs)
+	tui := fs.Bool("tui", false, "pick cells with the cursor keys on boards")
+	pos := fs.String("pos", "", "tic-tac-toe position to start from, e.g. \"X.O/.X./..O x\"")
+	fs.Parse(args)
+
+	e := opts.environment()
+	start := e.NewState(1)
+	if *pos != "" {
+		spec := opts.spec()
+		if spec.Game != "tictactoe" {
+			fmt.Println("-pos is only supported for tictactoe")
+			os.Exit(2)
+		}
+		b, err := board.Parse(spec.Board, *pos)
+		if err != nil {
+			fmt.Println("Invalid position:", err)
+			os.Exit(2)
+		}
+		start = b
+	}
+
+	// Create a human player
+	players := [3]player.Player{}
+	hp := player.NewHumanPlayer(human)
+	hp.SetHint(opts.spec().hint(human))
+	hp.SetTUI(*tui)
+	players[human] = hp
+	ai, err := opts.newAI(3 - human)
+	if err != nil {
+		fmt.Println("Error creating AI player:", err)
 		return
 	}
+	players[3-human] = ai
+
+	g := game.NewGameState(start, players[1], players[2], false)
+	result := g.Play()
+
+	g.GetState().Print()
+	switch {
+	case result == 0:
+		fmt.Println("Game abandoned")
+	case g.GetState().Winner() == 0:
+		fmt.Printf("Player %d resigned\n", 3-result)
+		fallthrough
+	default:
+		fmt.Println("Game result:", result)
+	}
 
-	fmt.Println("Invalid command. Use 'train', 'playX', or 'playO'.")
+	if rp, ok := ai.(*remote.RemotePlayer); ok {
+		if err := rp.Err(); err != nil {
+			fmt.Println("Error playing engine:", err)
+		}
+		rp.Close()
+	}
+}
+
+// boardFlags registers the board size flags on fs. The returned function
+// gives the configuration once fs has been parsed, exiting if it is invalid.
+func boardFlags(fs *flag.FlagSet) func() board.Config {
+	width := fs.Int("width", board.TicTacToe.Width, "board width")
+	height := fs.Int("height", board.TicTacToe.Height, "board height")
+	k := fs.Int("k", board.TicTacToe.K, "marks in a row needed to win")
+	rules := fs.String("rules", "standard", "rule variant: standard, misere, wild, notakto or nodraws")
+	return func() board.Config {
+		cfg := board.Config{Width: *width, Height: *height, K: *k}
+		var err error
+		if cfg.Rules, err = board.ParseRules(*rules); err != nil {
+			fmt.Println("Invalid board:", err)
+			os.Exit(2)
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Println("Invalid board:", err)
+			os.Exit(2)
+		}
+		return cfg
+	}
 }
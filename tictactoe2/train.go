@@ -0,0 +1,277 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/curriculum"
+	"github.com/param108/reinforcement-learning/tictactoe2/eval"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/league"
+	"github.com/param108/reinforcement-learning/tictactoe2/metrics"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// trainer plays the phases of a training run, evaluates the learner every
+// evalEvery games and writes a metrics record every metricsEvery games.
+type trainer struct {
+	env          board.Environment
+	learner      *player.LearnerPlayer
+	evaluator    *eval.Evaluator
+	evalEvery    int
+	played       int // Games played across all phases
+	metrics      metrics.Writer
+	metricsEvery int
+	window       *metrics.Window
+	lastRecord   time.Time // When the previous metrics record was written
+	lastPlayed   int       // Games played at the previous metrics record
+
+	// Self-play against a pool of snapshots of the learner, taken every
+	// snapshotEvery games. pool is nil without self-play.
+	pool          *league.Pool
+	snapshotEvery int
+	opponent      *league.Snapshot // Snapshot playing the current game, if any
+
+	// Starting positions of the games, nil to start from the empty board.
+	curriculum curriculum.Curriculum
+	total      int // Games in all phases, for the curriculum's progress
+}
+
+func train(args []string) {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	games := fs.Int("games", 10000, "games per training phase")
+	evalEvery := fs.Int("eval-every", 5000, "evaluate the learner every N games (0 disables)")
+	evalGames := fs.Int("eval-games", 100, "games per opponent and seat in each evaluation")
+	metricsPath := fs.String("metrics", "", "write training metrics to this .csv or .jsonl file")
+	metricsEvery := fs.Int("metrics-every", 500, "games between metrics records, also the size of the rolling window")
+	model := fs.String("model", "learner_player.json", "file to save the model to")
+	selfPlay := fs.Bool("self-play", false, "play the last two phases against snapshots of the learner instead of new learners")
+	snapshotEvery := fs.Int("snapshot-every", 1000, "games between snapshots of the learner in self-play")
+	poolSize := fs.Int("pool-size", 20, "snapshots kept in self-play, dropping the oldest")
+	sampling := fs.String("sampling", "uniform", "how self-play picks snapshots: uniform or prioritized (by the learner's losses against them)")
+	bitboard := fs.Bool("bitboard", false, "play on bitboards, which is faster and gives the same model")
+	curriculumKind := fs.String("curriculum", "none", "where games start: none (the empty board), endgame (near the end first, working back to the empty board), uniform (any reachable position) or blunders (where the learner goes wrong)")
+	curriculumMix := fs.Float64("curriculum-mix", 0.5, "fraction of games started by the curriculum, the rest starting from the empty board")
+	curriculumEvery := fs.Int("curriculum-every", 1000, "games between searches for the learner's blunders")
+	boardConfig := boardFlags(fs)
+	fs.Parse(args)
+
+	cfg := boardConfig()
+	solvable := cfg.Cells() <= board.TicTacToe.Cells()
+	if !solvable && *evalEvery > 0 {
+		// the evaluator searches the full game tree, which is only
+		// feasible for boards up to 3x3
+		fmt.Println("Evaluation is only supported on boards of up to 9 cells, disabling it.")
+		*evalEvery = 0
+	}
+
+	if *metricsEvery <= 0 {
+		fmt.Println("-metrics-every must be positive")
+		os.Exit(2)
+	}
+
+	t := &trainer{
+		env:          board.Environment{Config: cfg, Bitboard: *bitboard},
+		learner:      player.NewLearnerPlayer(1, 0.2, 0.1, "learner"),
+		evaluator:    eval.NewEvaluator(board.Environment{Config: cfg, Bitboard: *bitboard}, *evalGames),
+		evalEvery:    *evalEvery,
+		metricsEvery: *metricsEvery,
+		window:       metrics.NewWindow(*metricsEvery),
+		lastRecord:   time.Now(),
+	}
+
+	if *selfPlay {
+		if *snapshotEvery <= 0 || *poolSize <= 0 {
+			fmt.Println("-snapshot-every and -pool-size must be positive")
+			os.Exit(2)
+		}
+		sampling, err := league.ParseSampling(*sampling)
+		if err != nil {
+			fmt.Println("Invalid sampling:", err)
+			os.Exit(2)
+		}
+		t.pool = league.NewPool(*poolSize, sampling)
+		t.snapshotEvery = *snapshotEvery
+	}
+
+	kind, err := curriculum.ParseKind(*curriculumKind)
+	if err != nil {
+		fmt.Println("Invalid curriculum:", err)
+		os.Exit(2)
+	}
+	root := t.env.NewState(1)
+	switch kind {
+	case curriculum.Endgame:
+		t.curriculum = curriculum.NewEndgame(root, cfg.Cells()-1)
+	case curriculum.Uniform, curriculum.Blunders:
+		if !solvable {
+			fmt.Println("The", kind, "curriculum searches every position and is only supported on boards of up to 9 cells")
+			os.Exit(2)
+		}
+		if kind == curriculum.Uniform {
+			t.curriculum = curriculum.NewUniform(root)
+		} else if *curriculumEvery <= 0 {
+			fmt.Println("-curriculum-every must be positive")
+			os.Exit(2)
+		} else {
+			t.curriculum = curriculum.NewBlunders(root, t.learner, *curriculumEvery)
+		}
+	}
+	if t.curriculum != nil {
+		t.curriculum = curriculum.Mix(t.curriculum, root, *curriculumMix)
+		t.total = 4 * *games
+	}
+
+	if *metricsPath != "" {
+		w, err := metrics.NewWriter(*metricsPath)
+		if err != nil {
+			fmt.Println("Error creating metrics file:", err)
+			os.Exit(2)
+		}
+		defer w.Close()
+		t.metrics = w
+	}
+
+	if solvable {
+		t.phase("X", "X vs minimax", *games, func() player.Player { return player.NewOraclePlayer(2) })
+		t.phase("O", "O vs minimax", *games, func() player.Player { return player.NewOraclePlayer(1) })
+	} else {
+		// full minimax search is too slow on larger boards
+		t.phase("X", "X vs random", *games, func() player.Player { return player.NewRandomPlayer(2) })
+		t.phase("O", "O vs random", *games, func() player.Player { return player.NewRandomPlayer(1) })
+	}
+	if t.pool != nil {
+		if t.pool.Len() == 0 {
+			t.pool.Add(t.learner, t.played)
+		}
+		t.phase("X", "X vs league", *games, func() player.Player { return t.sample(2) })
+		t.phase("O", "O vs league", *games, func() player.Player { return t.sample(1) })
+		t.printPool()
+	} else {
+		t.phase("X", "X vs learner", *games, func() player.Player { return player.NewLearnerPlayer(2, 0.2, 0.1, "learner") })
+		t.phase("O", "O vs learner", *games, func() player.Player { return player.NewLearnerPlayer(1, 0.2, 0.1, "learner") })
+	}
+
+	if t.evalEvery > 0 && t.played%t.evalEvery != 0 {
+		t.evaluate()
+	}
+
+	if err := t.learner.SaveModel(*model); err != nil {
+		fmt.Println("Error saving model:", err)
+		os.Exit(1)
+	}
+}
+
+// phase plays games games with the learner as side ("X" or "O") against a
+// fresh opponent from newOpponent each game. name labels the phase in the
+// metrics.
+func (t *trainer) phase(side string, name string, games int, newOpponent func() player.Player) {
+	seat := 1
+	if side == "O" {
+		seat = 2
+	}
+	t.learner.SetPlayer(seat)
+
+	wins := 0
+	lose := 0
+	draw := 0
+	for i := 0; i < games; i++ {
+		fmt.Print("\r", "Playing as ", side, " ", i)
+		opponent := newOpponent()
+
+		start := t.env.NewState(1)
+		if t.curriculum != nil {
+			start = t.curriculum.Start(seat, float64(t.played)/float64(t.total))
+		}
+		var g *game.Game
+		if seat == 1 {
+			g = game.NewGameState(start, t.learner, opponent, true)
+		} else {
+			g = game.NewGameState(start, opponent, t.learner, true)
+		}
+		result := g.Play()
+		outcome := eval.Result{}
+		if result == t.learner.GetPlayer() {
+			wins++
+			t.window.Add(1)
+			outcome.Wins++
+		} else if result == 3 {
+			draw++
+			t.window.Add(0)
+			outcome.Draws++
+		} else {
+			lose++
+			t.window.Add(-1)
+			outcome.Losses++
+		}
+		if t.opponent != nil {
+			t.opponent.Results = t.opponent.Results.Add(outcome)
+			t.opponent = nil
+		}
+
+		t.played++
+		if t.pool != nil && t.played%t.snapshotEvery == 0 {
+			t.pool.Add(t.learner, t.played)
+		}
+		if t.metrics != nil && t.played%t.metricsEvery == 0 {
+			t.record(name)
+		}
+		if t.evalEvery > 0 && t.played%t.evalEvery == 0 {
+			t.evaluate()
+			t.learner.SetPlayer(seat)
+		}
+	}
+	fmt.Println("\nTraining as", side, "finished. Wins:", wins, "Draws:", draw, "Losses:", lose)
+}
+
+// sample draws a snapshot from the pool to play as seat.
+func (t *trainer) sample(seat int) player.Player {
+	t.opponent = t.pool.Sample()
+	t.opponent.Player.SetPlayer(seat)
+	return t.opponent.Player
+}
+
+// printPool prints the learner's results against each snapshot.
+func (t *trainer) printPool() {
+	fmt.Println("Results against the snapshots in the pool:")
+	for _, s := range t.pool.Snapshots() {
+		r := s.Results
+		fmt.Printf("  snapshot after %d games: %d games, %d wins, %d draws, %d losses, score %.2f\n",
+			s.Games, r.Games(), r.Wins, r.Draws, r.Losses, s.Score())
+	}
+}
+
+func (t *trainer) evaluate() {
+	start := time.Now()
+	report := t.evaluator.Evaluate(t.learner)
+	fmt.Printf("\rEvaluation after %d games: %s\n", t.played, report)
+
+	// keep evaluation time out of the training throughput
+	t.lastRecord = t.lastRecord.Add(time.Since(start))
+}
+
+func (t *trainer) record(phase string) {
+	now := time.Now()
+	winRate, drawRate, lossRate := t.window.Rates()
+	r := metrics.Record{
+		Games:          t.played,
+		Phase:          phase,
+		WinRate:        winRate,
+		DrawRate:       drawRate,
+		LossRate:       lossRate,
+		TDError:        t.learner.TakeTDError(),
+		ModelSize:      t.learner.ModelSize(),
+		Epsilon:        t.learner.GetEpsilon(),
+		LearningRate:   t.learner.GetLearningRate(),
+		GamesPerSecond: float64(t.played-t.lastPlayed) / now.Sub(t.lastRecord).Seconds(),
+	}
+	t.lastRecord = now
+	t.lastPlayed = t.played
+
+	if err := t.metrics.Write(r); err != nil {
+		fmt.Println("\nError writing metrics:", err)
+	}
+}
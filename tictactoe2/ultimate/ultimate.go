@@ -0,0 +1,269 @@
+// Package ultimate implements Ultimate tic-tac-toe: nine small boards
+// arranged in a 3x3 meta board. The cell a player marks decides which small
+// board the opponent must play in next; winning a small board claims that
+// cell of the meta board, and three claimed cells in a row win the game.
+package ultimate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/board"
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+)
+
+// Size is the width and height of the full grid of cells.
+const Size = 9
+
+// rules provides the 3x3 win logic shared by the small boards and the meta board.
+var rules = board.NewBoard(1)
+
+// State is an Ultimate tic-tac-toe position. Actions are cell indices
+// x + 9*y on the full 9x9 grid.
+type State struct {
+	cells  [9][9]int // Cells of each small board, both indexed row by row
+	boards [9]int    // Status of each small board as board.CheckWin reports it
+	forced int       // Small board the player to move must play in, or -1 for any
+	start  int
+	player int
+	status int
+}
+
+// Environment creates Ultimate tic-tac-toe games.
+type Environment struct{}
+
+func (Environment) Name() string {
+	return "ultimate"
+}
+
+func (Environment) NewState(start int) env.State {
+	return New(start)
+}
+
+func New(start int) *State {
+	return &State{
+		forced: -1,
+		start:  start,
+		player: start,
+	}
+}
+
+// split returns the small board and the cell within it of action a.
+func split(a env.Action) (int, int) {
+	x, y := int(a)%Size, int(a)/Size
+	return (y/3)*3 + x/3, (y%3)*3 + x%3
+}
+
+func join(b, c int) env.Action {
+	x := (b%3)*3 + c%3
+	y := (b/3)*3 + c/3
+	return env.Action(x + Size*y)
+}
+
+func (s *State) Player() int {
+	return s.player
+}
+
+// Forced returns the small board the player to move must play in, or -1 if
+// any unfinished board may be played.
+func (s *State) Forced() int {
+	return s.forced
+}
+
+func (s *State) Actions() []env.Action {
+	actions := []env.Action{}
+	if s.status != 0 {
+		return actions
+	}
+	for b := 0; b < 9; b++ {
+		if (s.forced >= 0 && b != s.forced) || s.boards[b] != 0 {
+			continue
+		}
+		for c := 0; c < 9; c++ {
+			if s.cells[b][c] == 0 {
+				actions = append(actions, join(b, c))
+			}
+		}
+	}
+	return actions
+}
+
+func (s *State) legal(a env.Action) bool {
+	if s.status != 0 || a < 0 || int(a) >= Size*Size {
+		return false
+	}
+	b, c := split(a)
+	return (s.forced < 0 || b == s.forced) && s.boards[b] == 0 && s.cells[b][c] == 0
+}
+
+func (s *State) Apply(a env.Action) (env.State, bool) {
+	if !s.legal(a) {
+		return nil, false
+	}
+
+	b, c := split(a)
+	next := *s
+	next.cells[b][c] = s.player
+	next.boards[b] = rules.CalcWin(next.cells[b][:])
+	if next.boards[b] != 0 {
+		next.status = rules.CalcWin(next.meta())
+	}
+
+	// the opponent plays in the board matching the cell, unless it is finished
+	next.forced = c
+	if next.boards[c] != 0 {
+		next.forced = -1
+	}
+	next.player = 3 - s.player
+	return &next, true
+}
+
+// meta returns the meta board for board.CalcWin. Drawn small boards get
+// distinct markers so that they fill the meta board without ever forming a
+// line.
+func (s *State) meta() []int {
+	m := make([]int, 9)
+	for b, status := range s.boards {
+		m[b] = status
+		if status == 3 {
+			m[b] = 10 + b
+		}
+	}
+	return m
+}
+
+func (s *State) Winner() int {
+	return s.status
+}
+
+func (s *State) Reward(player int) float64 {
+	return env.TerminalReward(s.status, player)
+}
+
+// Key writes each cell as a digit, followed by the forced board (9 for
+// any), the start player and the player to move.
+func (s *State) Key() string {
+	var sb strings.Builder
+	sb.Grow(Size*Size + 3)
+	for b := 0; b < 9; b++ {
+		for c := 0; c < 9; c++ {
+			sb.WriteByte(byte('0' + s.cells[b][c]))
+		}
+	}
+	forced := s.forced
+	if forced < 0 {
+		forced = 9
+	}
+	sb.WriteByte(byte('0' + forced))
+	sb.WriteByte(byte('0' + s.start))
+	sb.WriteByte(byte('0' + s.player))
+	return sb.String()
+}
+
+// FormatAction formats a as "x y" on the full 9x9 grid.
+func (s *State) FormatAction(a env.Action) string {
+	return fmt.Sprintf("%d %d", int(a)%Size, int(a)/Size)
+}
+
+// ParseAction parses a move written as "x y" on the full 9x9 grid.
+func (s *State) ParseAction(text string) (env.Action, error) {
+	parts := strings.Fields(text)
+	if len(parts) != 2 {
+		return 0, errors.New("please enter two numbers separated by a space")
+	}
+	x, err1 := strconv.Atoi(parts[0])
+	y, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || x < 0 || x >= Size || y < 0 || y >= Size {
+		return 0, fmt.Errorf("invalid coordinates, enter x and y between 0 and %d", Size-1)
+	}
+	a := env.Action(x + Size*y)
+	if !s.legal(a) {
+		if s.forced >= 0 {
+			return 0, fmt.Errorf("you must play an empty cell in board %d", s.forced)
+		}
+		return 0, errors.New("cell already taken or its board is finished")
+	}
+	return a, nil
+}
+
+func (s *State) Print() {
+	symbols := map[int]string{0: ".", 1: "X", 2: "O"}
+	fmt.Println("Current Board:")
+	for y := 0; y < Size; y++ {
+		if y > 0 && y%3 == 0 {
+			fmt.Println("-------+-------+-------")
+		}
+		for x := 0; x < Size; x++ {
+			if x > 0 && x%3 == 0 {
+				fmt.Print(" |")
+			}
+			b, c := split(env.Action(x + Size*y))
+			fmt.Print(" ", symbols[s.cells[b][c]])
+		}
+		fmt.Println()
+	}
+	for b, status := range s.boards {
+		if status == 1 || status == 2 {
+			fmt.Printf("Board %d won by %s\n", b, symbols[status])
+		}
+	}
+	if s.forced >= 0 {
+		fmt.Println("Must play in board", s.forced)
+	}
+	fmt.Println("Next Player:", s.player)
+}
+
+// Evaluate scores the position for player between -1 and 1 from the small
+// boards won, weighting the centre board, and from two-in-a-row threats on
+// the meta board.
+func (s *State) Evaluate(player int) float64 {
+	score := 0.0
+	for b, status := range s.boards {
+		weight := 1.0
+		if b == 4 {
+			weight = 1.5
+		}
+		switch status {
+		case player:
+			score += weight
+		case 3 - player:
+			score -= weight
+		}
+	}
+
+	// lines on the meta board that one side could still complete
+	meta := s.meta()
+	for _, line := range metaLines {
+		mine, theirs, blocked := 0, 0, false
+		for _, b := range line {
+			switch meta[b] {
+			case 0:
+			case player:
+				mine++
+			case 3 - player:
+				theirs++
+			default:
+				blocked = true
+			}
+		}
+		if blocked || (mine > 0 && theirs > 0) {
+			continue
+		}
+		if mine == 2 {
+			score += 0.5
+		} else if theirs == 2 {
+			score -= 0.5
+		}
+	}
+
+	return math.Tanh(score / 4)
+}
+
+var metaLines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, // rows
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8}, // columns
+	{0, 4, 8}, {2, 4, 6}, // diagonals
+}
@@ -0,0 +1,83 @@
+package ultimate
+
+import (
+	"testing"
+
+	"github.com/param108/reinforcement-learning/tictactoe2/env"
+	"github.com/param108/reinforcement-learning/tictactoe2/game"
+	"github.com/param108/reinforcement-learning/tictactoe2/player"
+)
+
+// play applies moves given as (board, cell) pairs.
+func play(t *testing.T, s *State, moves ...[2]int) *State {
+	for _, m := range moves {
+		next, ok := s.Apply(join(m[0], m[1]))
+		if !ok {
+			t.Fatalf("move %v rejected", m)
+		}
+		s = next.(*State)
+	}
+	return s
+}
+
+// Test that the cell played sends the opponent to the matching board
+func TestForcedBoard(t *testing.T) {
+	s := play(t, New(1), [2]int{4, 2})
+	if s.Forced() != 2 {
+		t.Fatalf("Forced() = %d; want 2", s.Forced())
+	}
+	if _, ok := s.Apply(join(5, 0)); ok {
+		t.Errorf("move outside the forced board accepted")
+	}
+	for _, a := range s.Actions() {
+		if b, _ := split(a); b != 2 {
+			t.Errorf("action %v is in board %d; want 2", a, b)
+		}
+	}
+}
+
+// Test that a won board frees the opponent to play anywhere
+func TestWonBoard(t *testing.T) {
+	s := play(t, New(1),
+		[2]int{0, 0}, [2]int{0, 3}, // X in board 0, O sent to board 0
+		[2]int{3, 0}, [2]int{0, 4},
+		[2]int{4, 0}, [2]int{0, 5}, // O wins board 0 with cells 3, 4, 5
+	)
+	if s.boards[0] != 2 {
+		t.Fatalf("board 0 status = %d; want 2", s.boards[0])
+	}
+	// X is sent to board 5, which is open
+	s = play(t, s, [2]int{5, 0})
+	// O is sent to the finished board 0 and may play anywhere else
+	if s.Forced() != -1 {
+		t.Errorf("Forced() = %d; want -1", s.Forced())
+	}
+	for _, a := range s.Actions() {
+		if b, _ := split(a); b == 0 {
+			t.Errorf("action %v is in the finished board 0", a)
+		}
+	}
+}
+
+// Test that three drawn boards in a row do not end the game
+func TestDrawnBoardsDoNotFormLines(t *testing.T) {
+	s := New(1)
+	for b := 0; b < 3; b++ {
+		s.boards[b] = 3
+	}
+	if got := rules.CalcWin(s.meta()); got != 0 {
+		t.Errorf("meta status = %d; want 0", got)
+	}
+}
+
+// Test that MCTS and alpha-beta can play full games
+func TestFullGame(t *testing.T) {
+	g := game.NewGameState(New(1), player.NewMCTSPlayer(1, 50), player.NewAlphaBetaPlayer(2, 2), true)
+	if result := g.Play(); result < 1 || result > 3 {
+		t.Errorf("Play() = %d; want 1, 2 or 3", result)
+	}
+	var s env.State = g.GetState()
+	if len(s.Actions()) != 0 {
+		t.Errorf("finished game has %d actions", len(s.Actions()))
+	}
+}